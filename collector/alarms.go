@@ -0,0 +1,156 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	alarmLabels    []string = []string{"entity_type", "entity_name", "alarm_name", "status", "acknowledged"}
+	alarmTriggered          = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "alarm",
+			Name:      "triggered",
+			Help:      "Triggered alarm, value is always 1",
+		},
+		alarmLabels,
+	)
+	alarmTriggeredTime = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "alarm",
+			Name:      "triggered_timestamp_seconds",
+			Help:      "Triggered alarm time in seconds since epoch",
+		},
+		alarmLabels,
+	)
+
+	// alarmEntityTypes are the entity types alarms are exported for
+	alarmEntityTypes = []string{"Datacenter", "ClusterComputeResource", "HostSystem", "Datastore", "VirtualMachine", "Network"}
+)
+
+func ExportAlarmMetrics(ctx context.Context, client *govmomi.Client) error {
+
+	c := client.Client
+
+	// Create a view manager
+	m := view.NewManager(c)
+
+	containerView, err := m.CreateContainerView(ctx, c.ServiceContent.RootFolder, alarmEntityTypes, true)
+	if err != nil {
+		log.Printf("Error creating container view: %v", err)
+		return err
+	}
+	defer containerView.Destroy(ctx)
+
+	var entities []mo.ManagedEntity
+	err = containerView.Retrieve(ctx, alarmEntityTypes, []string{"name", "triggeredAlarmState"}, &entities)
+	if err != nil {
+		log.Printf("Error retrieving triggered alarms: %v", err)
+		return err
+	}
+
+	// The triggered alarms of an entity include those of its descendants,
+	// keep every alarm state once
+	names := make(map[types.ManagedObjectReference]string)
+	states := make(map[string]types.AlarmState)
+	for _, entity := range entities {
+		names[entity.Self] = entity.Name
+		for _, state := range entity.TriggeredAlarmState {
+			states[state.Key] = state
+		}
+	}
+
+	// Resolve the alarm definition names and the names of entities outside
+	// the view
+	var alarmRefs, entityRefs []types.ManagedObjectReference
+	seen := make(map[types.ManagedObjectReference]bool)
+	for _, state := range states {
+		if !seen[state.Alarm] {
+			seen[state.Alarm] = true
+			alarmRefs = append(alarmRefs, state.Alarm)
+		}
+		if _, ok := names[state.Entity]; !ok && !seen[state.Entity] {
+			seen[state.Entity] = true
+			entityRefs = append(entityRefs, state.Entity)
+		}
+	}
+
+	pc := property.DefaultCollector(c)
+
+	alarmNames := make(map[types.ManagedObjectReference]string)
+	if len(alarmRefs) > 0 {
+		var alarms []mo.Alarm
+		if err = pc.Retrieve(ctx, alarmRefs, []string{"info.name"}, &alarms); err != nil {
+			log.Printf("Error retrieving alarm definitions: %v", err)
+			return err
+		}
+		for _, alarm := range alarms {
+			alarmNames[alarm.Self] = alarm.Info.Name
+		}
+	}
+
+	if len(entityRefs) > 0 {
+		var others []mo.ManagedEntity
+		if err = pc.Retrieve(ctx, entityRefs, []string{"name"}, &others); err != nil {
+			log.Printf("Error retrieving alarm entities: %v", err)
+			return err
+		}
+		for _, entity := range others {
+			names[entity.Self] = entity.Name
+		}
+	}
+
+	// Cleared alarms must disappear
+	alarmTriggered.Reset()
+	alarmTriggeredTime.Reset()
+
+	for _, state := range states {
+		alarmName := alarmNames[state.Alarm]
+		if alarmName == "" {
+			alarmName = state.Alarm.Value
+		}
+
+		labels := prometheus.Labels{
+			"entity_type":  alarmEntityType(state.Entity.Type),
+			"entity_name":  names[state.Entity],
+			"alarm_name":   alarmName,
+			"status":       string(state.OverallStatus),
+			"acknowledged": fmt.Sprintf("%t", state.Acknowledged != nil && *state.Acknowledged),
+		}
+
+		alarmTriggered.With(labels).Set(1)
+		alarmTriggeredTime.With(labels).Set(
+			float64(state.Time.Unix()),
+		)
+	}
+	return nil
+}
+
+func alarmEntityType(moType string) string {
+	switch moType {
+	case "Datacenter":
+		return "datacenter"
+	case "ClusterComputeResource":
+		return "cluster"
+	case "HostSystem":
+		return "host"
+	case "Datastore":
+		return "datastore"
+	case "VirtualMachine":
+		return "vm"
+	case "Network", "DistributedVirtualPortgroup", "OpaqueNetwork":
+		return "network"
+	}
+	return moType
+}
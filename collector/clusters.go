@@ -2,7 +2,7 @@ package collector
 
 import (
 	"context"
-	"fmt"
+	"log"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -96,7 +96,7 @@ func ExportClusterMetrics(ctx context.Context, client *govmomi.Client) error {
 	// Create a container view for clusters
 	containerView, err := m.CreateContainerView(ctx, c.ServiceContent.RootFolder, []string{"ClusterComputeResource"}, true)
 	if err != nil {
-		fmt.Printf("Error creating container view: %v\n", err)
+		log.Printf("Error creating container view: %v", err)
 		return err
 	}
 	defer containerView.Destroy(ctx)
@@ -104,10 +104,14 @@ func ExportClusterMetrics(ctx context.Context, client *govmomi.Client) error {
 	// Retrieve a list of clusters
 	var clusters []mo.ClusterComputeResource
 	if err = containerView.Retrieve(ctx, []string{"ClusterComputeResource"}, nil, &clusters); err != nil {
-		fmt.Printf("Error retrieving clusters: %v\n", err)
+		log.Printf("Error retrieving clusters: %v", err)
 		return err
 	}
 
+	// Removed clusters and changed HA host states must disappear
+	clusterInfo.Reset()
+	clusterHaHostState.Reset()
+
 	// Iterate through the clusters and print provisioning vs. available resources
 	for _, cluster := range clusters {
 
@@ -146,6 +150,12 @@ func ExportClusterMetrics(ctx context.Context, client *govmomi.Client) error {
 		clusterThreadsNum.With(labels).Set(
 			float64(cluster.Summary.GetComputeResourceSummary().NumCpuThreads),
 		)
+
+		exportClusterConfigMetrics(cluster, labels)
+	}
+
+	if err = exportClusterHaHostStates(ctx, c, clusters); err != nil {
+		return err
 	}
-	return nil
+	return exportClusterRuleMetrics(ctx, c, clusters)
 }
@@ -0,0 +1,245 @@
+package collector
+
+import (
+	"context"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	clusterInfoLabels        []string = []string{"cluster_name", "cluster_id", "drs_automation_level", "ha_admission_control_policy", "ha_host_monitoring", "ha_vm_monitoring"}
+	clusterHaHostStateLabels []string = []string{"cluster_name", "cluster_id", "host_name", "state"}
+	clusterInfo                       = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "info",
+			Help:      "Cluster DRS and HA configuration, value is always 1",
+		},
+		clusterInfoLabels,
+	)
+	clusterOverallStatus = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "overall_status",
+			Help:      "Cluster overall status (0 = green, 1 = yellow, 2 = red, 3 = gray)",
+		},
+		clusterLabels,
+	)
+	clusterDrsEnabled = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "drs_enabled",
+			Help:      "Cluster DRS enabled (1 = enabled, 0 = disabled)",
+		},
+		clusterLabels,
+	)
+	clusterDrsAutomationLevel = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "drs_automation_level",
+			Help:      "Cluster DRS automation level (0 = manual, 1 = partially automated, 2 = fully automated)",
+		},
+		clusterLabels,
+	)
+	clusterDrsMigrationThreshold = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "drs_migration_threshold",
+			Help:      "Cluster DRS migration threshold as shown in the vSphere Client (1 = conservative, 5 = aggressive)",
+		},
+		clusterLabels,
+	)
+	clusterDrsScore = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "drs_score",
+			Help:      "Cluster DRS score in percent",
+		},
+		clusterLabels,
+	)
+	clusterHaEnabled = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "ha_enabled",
+			Help:      "Cluster vSphere HA enabled (1 = enabled, 0 = disabled)",
+		},
+		clusterLabels,
+	)
+	clusterHaAdmissionControlEnabled = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "ha_admission_control_enabled",
+			Help:      "Cluster vSphere HA admission control enabled (1 = enabled, 0 = disabled)",
+		},
+		clusterLabels,
+	)
+	clusterHaFailoverLevel = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "ha_failover_level",
+			Help:      "Cluster host failures the HA admission control policy tolerates",
+		},
+		clusterLabels,
+	)
+	clusterHaFailoverLevelCurrent = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "ha_failover_level_current",
+			Help:      "Cluster host failures the current HA failover capacity tolerates",
+		},
+		clusterLabels,
+	)
+	clusterHaHostState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "ha_host_state",
+			Help:      "vSphere HA state of a cluster host, value is always 1",
+		},
+		clusterHaHostStateLabels,
+	)
+)
+
+func exportClusterConfigMetrics(cluster mo.ClusterComputeResource, labels prometheus.Labels) {
+	clusterOverallStatus.With(labels).Set(
+		managedEntityStatusValue(cluster.OverallStatus),
+	)
+
+	if summary, ok := cluster.Summary.(*types.ClusterComputeResourceSummary); ok {
+		clusterHaFailoverLevelCurrent.With(labels).Set(
+			float64(summary.CurrentFailoverLevel),
+		)
+		// vCenter before 7.0 has no DRS score
+		if summary.DrsScore > 0 {
+			clusterDrsScore.With(labels).Set(float64(summary.DrsScore))
+		} else {
+			clusterDrsScore.Delete(labels)
+		}
+	}
+
+	config, ok := cluster.ConfigurationEx.(*types.ClusterConfigInfoEx)
+	if !ok {
+		return
+	}
+	drs := config.DrsConfig
+	das := config.DasConfig
+
+	clusterDrsEnabled.With(labels).Set(
+		boolValue(drs.Enabled != nil && *drs.Enabled),
+	)
+	if drs.DefaultVmBehavior != "" {
+		clusterDrsAutomationLevel.With(labels).Set(
+			drsBehaviorValue(drs.DefaultVmBehavior),
+		)
+	}
+	if drs.VmotionRate > 0 {
+		clusterDrsMigrationThreshold.With(labels).Set(
+			float64(6 - drs.VmotionRate),
+		)
+	}
+
+	clusterHaEnabled.With(labels).Set(
+		boolValue(das.Enabled != nil && *das.Enabled),
+	)
+	clusterHaAdmissionControlEnabled.With(labels).Set(
+		boolValue(das.AdmissionControlEnabled != nil && *das.AdmissionControlEnabled),
+	)
+
+	policy, failoverLevel := admissionControlPolicy(das.AdmissionControlPolicy)
+	if failoverLevel > 0 {
+		clusterHaFailoverLevel.With(labels).Set(float64(failoverLevel))
+	} else {
+		clusterHaFailoverLevel.Delete(labels)
+	}
+
+	clusterInfo.With(prometheus.Labels{
+		"cluster_name":                labels["cluster_name"],
+		"cluster_id":                  labels["cluster_id"],
+		"drs_automation_level":        string(drs.DefaultVmBehavior),
+		"ha_admission_control_policy": policy,
+		"ha_host_monitoring":          das.HostMonitoring,
+		"ha_vm_monitoring":            das.VmMonitoring,
+	}).Set(1)
+}
+
+// exportClusterHaHostStates exports the vSphere HA state of the cluster hosts,
+// hosts without HA have no state
+func exportClusterHaHostStates(ctx context.Context, c *vim25.Client, clusters []mo.ClusterComputeResource) error {
+	clusterOf := make(map[types.ManagedObjectReference]mo.ClusterComputeResource)
+	var refs []types.ManagedObjectReference
+	for _, cluster := range clusters {
+		for _, host := range cluster.Host {
+			clusterOf[host] = cluster
+			refs = append(refs, host)
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	var hosts []mo.HostSystem
+	pc := property.DefaultCollector(c)
+	if err := pc.Retrieve(ctx, refs, []string{"name", "runtime.dasHostState"}, &hosts); err != nil {
+		log.Printf("Error retrieving cluster hosts: %v", err)
+		return err
+	}
+
+	for _, host := range hosts {
+		if host.Runtime.DasHostState == nil {
+			continue
+		}
+		cluster := clusterOf[host.Self]
+
+		clusterHaHostState.With(prometheus.Labels{
+			"cluster_name": cluster.Name,
+			"cluster_id":   cluster.Self.Value,
+			"host_name":    host.Name,
+			"state":        host.Runtime.DasHostState.State,
+		}).Set(1)
+	}
+	return nil
+}
+
+// drsBehaviorValue maps the DRS automation level to
+// manual/partiallyAutomated/fullyAutomated 0/1/2
+func drsBehaviorValue(behavior types.DrsBehavior) float64 {
+	switch behavior {
+	case types.DrsBehaviorPartiallyAutomated:
+		return 1
+	case types.DrsBehaviorFullyAutomated:
+		return 2
+	}
+	return 0
+}
+
+// admissionControlPolicy returns the name of the HA admission control policy
+// and the host failures it tolerates, if it is defined by host failures
+func admissionControlPolicy(policy types.BaseClusterDasAdmissionControlPolicy) (string, int32) {
+	switch p := policy.(type) {
+	case *types.ClusterFailoverLevelAdmissionControlPolicy:
+		return "slot_policy", p.FailoverLevel
+	case *types.ClusterFailoverResourcesAdmissionControlPolicy:
+		return "cluster_resource_percentage", p.FailoverLevel
+	case *types.ClusterFailoverHostAdmissionControlPolicy:
+		return "dedicated_failover_hosts", p.FailoverLevel
+	case nil:
+		return "none", 0
+	}
+	return "unknown", 0
+}
@@ -0,0 +1,232 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	clusterRuleLabels     []string = []string{"cluster_name", "cluster_id", "rule_name", "rule_type"}
+	clusterRuleInfoLabels []string = []string{"cluster_name", "cluster_id", "rule_name", "rule_type", "enabled", "mandatory"}
+	clusterRuleInfo                = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "rule_info",
+			Help:      "Cluster DRS rule, value is always 1",
+		},
+		clusterRuleInfoLabels,
+	)
+	clusterRuleMembers = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "rule_members",
+			Help:      "Cluster DRS rule member VMs",
+		},
+		clusterRuleLabels,
+	)
+	clusterRuleViolations = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "cluster",
+			Name:      "rule_violations",
+			Help:      "Cluster DRS rule powered on member VMs placed against the rule",
+		},
+		clusterRuleLabels,
+	)
+)
+
+// exportClusterRuleMetrics exports the VM-VM and VM-host rules of the
+// clusters, violations are computed from the hosts the member VMs run on
+func exportClusterRuleMetrics(ctx context.Context, c *vim25.Client, clusters []mo.ClusterComputeResource) error {
+	var refs []types.ManagedObjectReference
+	seen := make(map[types.ManagedObjectReference]bool)
+	addVms := func(vms []types.ManagedObjectReference) {
+		for _, vm := range vms {
+			if !seen[vm] {
+				seen[vm] = true
+				refs = append(refs, vm)
+			}
+		}
+	}
+
+	for _, cluster := range clusters {
+		config, ok := cluster.ConfigurationEx.(*types.ClusterConfigInfoEx)
+		if !ok {
+			continue
+		}
+		for _, rule := range config.Rule {
+			addVms(ruleVms(rule, nil))
+		}
+		for _, group := range config.Group {
+			if vmGroup, ok := group.(*types.ClusterVmGroup); ok {
+				addVms(vmGroup.Vm)
+			}
+		}
+	}
+
+	// The host of every powered on member VM
+	placement := make(map[types.ManagedObjectReference]types.ManagedObjectReference)
+	if len(refs) > 0 {
+		var vms []mo.VirtualMachine
+		pc := property.DefaultCollector(c)
+		if err := pc.Retrieve(ctx, refs, []string{"runtime.host", "runtime.powerState"}, &vms); err != nil {
+			log.Printf("Error retrieving cluster rule VMs: %v", err)
+			return err
+		}
+		for _, vm := range vms {
+			if vm.Runtime.Host != nil && vm.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+				placement[vm.Self] = *vm.Runtime.Host
+			}
+		}
+	}
+
+	// Removed rules must disappear, once the placement is known so a failed
+	// retrieval keeps the previous series
+	clusterRuleInfo.Reset()
+	clusterRuleMembers.Reset()
+	clusterRuleViolations.Reset()
+
+	for _, cluster := range clusters {
+		config, ok := cluster.ConfigurationEx.(*types.ClusterConfigInfoEx)
+		if !ok {
+			continue
+		}
+
+		vmGroups := make(map[string][]types.ManagedObjectReference)
+		hostGroups := make(map[string][]types.ManagedObjectReference)
+		for _, group := range config.Group {
+			switch g := group.(type) {
+			case *types.ClusterVmGroup:
+				vmGroups[g.Name] = g.Vm
+			case *types.ClusterHostGroup:
+				hostGroups[g.Name] = g.Host
+			}
+		}
+
+		for _, rule := range config.Rule {
+			info := rule.GetClusterRuleInfo()
+			ruleType := clusterRuleType(rule)
+
+			labels := prometheus.Labels{
+				"cluster_name": cluster.Name,
+				"cluster_id":   cluster.Self.Value,
+				"rule_name":    info.Name,
+				"rule_type":    ruleType,
+			}
+
+			clusterRuleInfo.With(prometheus.Labels{
+				"cluster_name": cluster.Name,
+				"cluster_id":   cluster.Self.Value,
+				"rule_name":    info.Name,
+				"rule_type":    ruleType,
+				"enabled":      fmt.Sprintf("%t", info.Enabled != nil && *info.Enabled),
+				"mandatory":    fmt.Sprintf("%t", info.Mandatory != nil && *info.Mandatory),
+			}).Set(1)
+
+			clusterRuleMembers.With(labels).Set(
+				float64(len(ruleVms(rule, vmGroups))),
+			)
+
+			// Dependency rules are about the power on order, not placement
+			if violations, ok := ruleViolations(rule, vmGroups, hostGroups, placement); ok {
+				clusterRuleViolations.With(labels).Set(float64(violations))
+			}
+		}
+	}
+	return nil
+}
+
+// ruleVms returns the member VMs of a rule, VM-host and dependency rules
+// resolve their VM groups if vmGroups is given
+func ruleVms(rule types.BaseClusterRuleInfo, vmGroups map[string][]types.ManagedObjectReference) []types.ManagedObjectReference {
+	switch r := rule.(type) {
+	case *types.ClusterAffinityRuleSpec:
+		return r.Vm
+	case *types.ClusterAntiAffinityRuleSpec:
+		return r.Vm
+	case *types.ClusterVmHostRuleInfo:
+		return vmGroups[r.VmGroupName]
+	case *types.ClusterDependencyRuleInfo:
+		return append(append([]types.ManagedObjectReference(nil), vmGroups[r.VmGroup]...), vmGroups[r.DependsOnVmGroup]...)
+	}
+	return nil
+}
+
+// ruleViolations returns the number of powered on member VMs placed against
+// the rule:
+//   - affinity: the VMs not running on the host most members run on
+//   - anti-affinity: the VMs sharing a host with another member
+//   - VM-host: the VMs running outside the affine or on the anti-affine hosts
+func ruleViolations(rule types.BaseClusterRuleInfo, vmGroups, hostGroups map[string][]types.ManagedObjectReference, placement map[types.ManagedObjectReference]types.ManagedObjectReference) (int, bool) {
+	perHost := func(vms []types.ManagedObjectReference) map[types.ManagedObjectReference]int {
+		counts := make(map[types.ManagedObjectReference]int)
+		for _, vm := range vms {
+			if host, ok := placement[vm]; ok {
+				counts[host]++
+			}
+		}
+		return counts
+	}
+
+	switch r := rule.(type) {
+	case *types.ClusterAffinityRuleSpec:
+		running, most := 0, 0
+		for _, count := range perHost(r.Vm) {
+			running += count
+			if count > most {
+				most = count
+			}
+		}
+		return running - most, true
+	case *types.ClusterAntiAffinityRuleSpec:
+		violations := 0
+		for _, count := range perHost(r.Vm) {
+			if count > 1 {
+				violations += count
+			}
+		}
+		return violations, true
+	case *types.ClusterVmHostRuleInfo:
+		affine := make(map[types.ManagedObjectReference]bool)
+		for _, host := range hostGroups[r.AffineHostGroupName] {
+			affine[host] = true
+		}
+		antiAffine := make(map[types.ManagedObjectReference]bool)
+		for _, host := range hostGroups[r.AntiAffineHostGroupName] {
+			antiAffine[host] = true
+		}
+
+		violations := 0
+		for host, count := range perHost(vmGroups[r.VmGroupName]) {
+			if (r.AffineHostGroupName != "" && !affine[host]) || antiAffine[host] {
+				violations += count
+			}
+		}
+		return violations, true
+	}
+	return 0, false
+}
+
+func clusterRuleType(rule types.BaseClusterRuleInfo) string {
+	switch rule.(type) {
+	case *types.ClusterAffinityRuleSpec:
+		return "affinity"
+	case *types.ClusterAntiAffinityRuleSpec:
+		return "anti_affinity"
+	case *types.ClusterVmHostRuleInfo:
+		return "vm_host"
+	case *types.ClusterDependencyRuleInfo:
+		return "dependency"
+	}
+	return "unknown"
+}
@@ -1,16 +1,36 @@
 package collector
 
+import (
+	"path"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
 var (
 	HostConfig            = make(map[string]float64)
 	HostMapping           = make(map[string]string)
 	VirtualMachineMapping = make(map[string]string)
 	DatastoreMapping      = make(map[string]string)
+	DatastoreUUIDMapping  = make(map[string]string)
+	NetworkMapping        = make(map[string]string)
 )
 
 func populateDatastoreMapping(datastoreID, datastoreName string) {
 	DatastoreMapping[datastoreID] = datastoreName
 }
 
+func populateDatastoreUUIDMapping(datastoreURL, datastoreName string) {
+	// ds:///vmfs/volumes/<uuid>/
+	uuid := path.Base(datastoreURL)
+	if uuid != "" && uuid != "/" && uuid != "." {
+		DatastoreUUIDMapping[uuid] = datastoreName
+	}
+}
+
+func populateNetworkMapping(networkID, networkName string) {
+	NetworkMapping[networkID] = networkName
+}
+
 func populateHostConfig(hostName string, cpuMhz float64) {
 	HostConfig[hostName] = cpuMhz
 }
@@ -22,3 +42,24 @@ func populateHostMapping(hostID, clusterName string) {
 func populateVirtualMachineMapping(hostID, hostName string) {
 	VirtualMachineMapping[hostID] = hostName
 }
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// managedEntityStatusValue maps the green/yellow/red/gray overall status to
+// 0/1/2/3
+func managedEntityStatusValue(status types.ManagedEntityStatus) float64 {
+	switch status {
+	case types.ManagedEntityStatusGreen:
+		return 0
+	case types.ManagedEntityStatusYellow:
+		return 1
+	case types.ManagedEntityStatusRed:
+		return 2
+	}
+	return 3
+}
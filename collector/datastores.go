@@ -2,16 +2,18 @@ package collector
 
 import (
 	"context"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/view"
 	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 var (
-	datastoresLabels []string = []string{"datastore_name", "datastore_type"}
+	datastoresLabels []string = []string{"datastore_name", "datastore_type", "datastore_cluster"}
 	dsCapacity                = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "vmware",
@@ -30,9 +32,24 @@ var (
 		},
 		datastoresLabels,
 	)
+
+	dsMetrics = []*prometheus.GaugeVec{
+		dsCapacity, dsFreeSpace, dsUncommitted, dsProvisioned, dsAccessible,
+		dsMaintenanceMode, dsMultipleHostAccess, dsVms, dsOverprovisioning, dsPredictedFull,
+	}
+
+	// dsLabels are the labels each datastore was last exported with, a
+	// renamed datastore or one moved into or out of a datastore cluster
+	// changes them
+	dsLabels = make(map[types.ManagedObjectReference]prometheus.Labels)
 )
 
 func ExportDatastoresMetrics(ctx context.Context, c *govmomi.Client) error {
+	pods, err := exportStoragePodMetrics(ctx, c.Client)
+	if err != nil {
+		return err
+	}
+
 	// Create a view of Datastore objects
 	m := view.NewManager(c.Client)
 
@@ -46,19 +63,39 @@ func ExportDatastoresMetrics(ctx context.Context, c *govmomi.Client) error {
 	// Retrieve summary property for all datastores
 	// Reference: http://pubs.vmware.com/vsphere-60/topic/com.vmware.wssdk.apiref.doc/vim.Datastore.html
 	var dss []mo.Datastore
-	err = v.Retrieve(ctx, []string{"Datastore"}, []string{"summary"}, &dss)
+	err = v.Retrieve(ctx, []string{"Datastore"}, []string{"summary", "parent", "info", "host", "vm"}, &dss)
 	if err != nil {
 		return err
 	}
 
+	// Removed datastores and unmounted hosts must disappear
+	dsInfo.Reset()
+	dsHostMounted.Reset()
+	dsHostAccessible.Reset()
+	dsHostReadOnly.Reset()
+
+	now := time.Now()
 	for _, ds := range dss {
 
 		populateDatastoreMapping(ds.Self.Value, ds.Summary.Name)
+		populateDatastoreUUIDMapping(ds.Summary.Url, ds.Summary.Name)
+
+		datastoreCluster := ""
+		if ds.Parent != nil {
+			datastoreCluster = pods[*ds.Parent]
+		}
 
 		labels := prometheus.Labels{
-			"datastore_name": ds.Summary.Name,
-			"datastore_type": ds.Summary.Type,
+			"datastore_name":    ds.Summary.Name,
+			"datastore_type":    ds.Summary.Type,
+			"datastore_cluster": datastoreCluster,
+		}
+		if previous, ok := dsLabels[ds.Self]; ok && !labelsEqual(previous, labels) {
+			for _, gauge := range dsMetrics {
+				gauge.Delete(previous)
+			}
 		}
+		dsLabels[ds.Self] = labels
 
 		dsCapacity.With(labels).Set(
 			float64(ds.Summary.Capacity),
@@ -66,6 +103,23 @@ func ExportDatastoresMetrics(ctx context.Context, c *govmomi.Client) error {
 		dsFreeSpace.With(labels).Set(
 			float64(ds.Summary.FreeSpace),
 		)
+
+		exportDatastoreHealthMetrics(ds, labels)
+		exportDatastoreForecastMetrics(ds, labels, now)
+	}
+	pruneFreeSpaceHistory(dss)
+
+	return exportDatastoreHostMounts(ctx, c.Client, dss)
+}
+
+func labelsEqual(a, b prometheus.Labels) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
 	}
-	return nil
+	return true
 }
@@ -0,0 +1,117 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// DatastoreForecastWindow is how far back the free space samples the
+// datastore full forecast is computed from go
+var DatastoreForecastWindow = 7 * 24 * time.Hour
+
+// dsForecastMinSamples is the number of samples needed before forecasting
+const dsForecastMinSamples = 3
+
+var (
+	dsOverprovisioning = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "overprovisioning_ratio",
+			Help:      "Datastore provisioned space divided by capacity",
+		},
+		datastoresLabels,
+	)
+	dsPredictedFull = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "predicted_full_timestamp_seconds",
+			Help:      "Datastore predicted full time in seconds since epoch, from a linear regression of the free space",
+		},
+		datastoresLabels,
+	)
+
+	// dsFreeSpaceHistory are the free space samples of each datastore within
+	// the forecast window, oldest first
+	dsFreeSpaceHistory = make(map[types.ManagedObjectReference][]freeSpaceSample)
+)
+
+type freeSpaceSample struct {
+	time time.Time
+	free float64
+}
+
+func exportDatastoreForecastMetrics(ds mo.Datastore, labels prometheus.Labels, now time.Time) {
+	summary := ds.Summary
+
+	if summary.Capacity > 0 {
+		dsOverprovisioning.With(labels).Set(
+			float64(summary.Capacity-summary.FreeSpace+summary.Uncommitted) / float64(summary.Capacity),
+		)
+	}
+
+	samples := append(dsFreeSpaceHistory[ds.Self], freeSpaceSample{time: now, free: float64(summary.FreeSpace)})
+	for len(samples) > 0 && now.Sub(samples[0].time) > DatastoreForecastWindow {
+		samples = samples[1:]
+	}
+	dsFreeSpaceHistory[ds.Self] = samples
+
+	full, ok := predictFull(samples)
+	if !ok {
+		dsPredictedFull.Delete(labels)
+		return
+	}
+	dsPredictedFull.With(labels).Set(full)
+}
+
+// pruneFreeSpaceHistory forgets the samples of removed datastores
+func pruneFreeSpaceHistory(dss []mo.Datastore) {
+	current := make(map[types.ManagedObjectReference]bool)
+	for _, ds := range dss {
+		current[ds.Self] = true
+	}
+	for ref := range dsFreeSpaceHistory {
+		if !current[ref] {
+			delete(dsFreeSpaceHistory, ref)
+		}
+	}
+}
+
+// predictFull fits a least squares line through the free space samples and
+// returns the time in seconds since epoch it reaches zero, there is no
+// prediction unless the free space is shrinking
+func predictFull(samples []freeSpaceSample) (float64, bool) {
+	if len(samples) < dsForecastMinSamples {
+		return 0, false
+	}
+
+	// Seconds since the first sample keep the sums small
+	start := samples[0].time
+	n := float64(len(samples))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		x := s.time.Sub(start).Seconds()
+		sumX += x
+		sumY += s.free
+		sumXY += x * s.free
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, false
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	if slope >= 0 {
+		return 0, false
+	}
+	intercept := (sumY - slope*sumX) / n
+
+	// A slowly shrinking datastore is full beyond what a time.Duration holds
+	return float64(start.UnixNano())/1e9 - intercept/slope, true
+}
@@ -0,0 +1,220 @@
+package collector
+
+import (
+	"context"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	dsInfoLabels      []string = []string{"datastore_name", "datastore_type", "vmfs_version", "nfs_remote_host", "nfs_remote_path"}
+	dsHostMountLabels []string = []string{"datastore_name", "host_name", "host_id", "cluster_name"}
+	dsInfo                     = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "info",
+			Help:      "Datastore VMFS version or NFS remote host and path, value is always 1",
+		},
+		dsInfoLabels,
+	)
+	dsUncommitted = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "uncommitted_bytes",
+			Help:      "Datastore space promised to thin provisioned disks but not yet used in bytes",
+		},
+		datastoresLabels,
+	)
+	dsProvisioned = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "provisioned_bytes",
+			Help:      "Datastore provisioned space, used plus uncommitted, in bytes",
+		},
+		datastoresLabels,
+	)
+	dsAccessible = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "accessible",
+			Help:      "Datastore accessible (1 = accessible, 0 = inaccessible)",
+		},
+		datastoresLabels,
+	)
+	dsMaintenanceMode = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "maintenance_mode",
+			Help:      "Datastore maintenance mode (0 = normal, 1 = entering maintenance, 2 = in maintenance)",
+		},
+		datastoresLabels,
+	)
+	dsMultipleHostAccess = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "multiple_host_access",
+			Help:      "Datastore accessible by more than one host (1 = shared, 0 = local)",
+		},
+		datastoresLabels,
+	)
+	dsVms = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "vms_total",
+			Help:      "Datastore VMs",
+		},
+		datastoresLabels,
+	)
+	dsHostMounted = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "host_mounted",
+			Help:      "Datastore mounted on the host (1 = mounted, 0 = unmounted)",
+		},
+		dsHostMountLabels,
+	)
+	dsHostAccessible = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "host_accessible",
+			Help:      "Datastore accessible from the host (1 = accessible, 0 = inaccessible)",
+		},
+		dsHostMountLabels,
+	)
+	dsHostReadOnly = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "host_read_only",
+			Help:      "Datastore mounted read-only on the host (1 = read-only, 0 = read-write)",
+		},
+		dsHostMountLabels,
+	)
+)
+
+func exportDatastoreHealthMetrics(ds mo.Datastore, labels prometheus.Labels) {
+	summary := ds.Summary
+
+	dsUncommitted.With(labels).Set(
+		float64(summary.Uncommitted),
+	)
+	dsProvisioned.With(labels).Set(
+		float64(summary.Capacity - summary.FreeSpace + summary.Uncommitted),
+	)
+	dsAccessible.With(labels).Set(
+		boolValue(summary.Accessible),
+	)
+	dsMaintenanceMode.With(labels).Set(
+		dsMaintenanceModeValue(summary.MaintenanceMode),
+	)
+	dsMultipleHostAccess.With(labels).Set(
+		boolValue(summary.MultipleHostAccess != nil && *summary.MultipleHostAccess),
+	)
+	dsVms.With(labels).Set(
+		float64(len(ds.Vm)),
+	)
+
+	infoLabels := prometheus.Labels{
+		"datastore_name":  summary.Name,
+		"datastore_type":  summary.Type,
+		"vmfs_version":    "",
+		"nfs_remote_host": "",
+		"nfs_remote_path": "",
+	}
+	switch info := ds.Info.(type) {
+	case *types.VmfsDatastoreInfo:
+		if info.Vmfs != nil {
+			infoLabels["vmfs_version"] = info.Vmfs.Version
+		}
+	case *types.NasDatastoreInfo:
+		if info.Nas != nil {
+			infoLabels["nfs_remote_host"] = info.Nas.RemoteHost
+			infoLabels["nfs_remote_path"] = info.Nas.RemotePath
+		}
+	}
+	dsInfo.With(infoLabels).Set(1)
+}
+
+// exportDatastoreHostMounts exports the mount state of the datastores on
+// each host, a datastore inaccessible on only some hosts points at an
+// APD or PDL condition
+func exportDatastoreHostMounts(ctx context.Context, c *vim25.Client, dss []mo.Datastore) error {
+	var refs []types.ManagedObjectReference
+	seen := make(map[types.ManagedObjectReference]bool)
+	for _, ds := range dss {
+		for _, mount := range ds.Host {
+			if !seen[mount.Key] {
+				seen[mount.Key] = true
+				refs = append(refs, mount.Key)
+			}
+		}
+	}
+
+	hostNames := make(map[types.ManagedObjectReference]string)
+	if len(refs) > 0 {
+		var hosts []mo.HostSystem
+		pc := property.DefaultCollector(c)
+		if err := pc.Retrieve(ctx, refs, []string{"name"}, &hosts); err != nil {
+			log.Printf("Error retrieving datastore hosts: %v", err)
+			return err
+		}
+		for _, host := range hosts {
+			hostNames[host.Self] = host.Name
+		}
+	}
+
+	for _, ds := range dss {
+		for _, mount := range ds.Host {
+			clusterName := HostMapping[mount.Key.Value]
+			if clusterName == "" {
+				clusterName = "none"
+			}
+
+			labels := prometheus.Labels{
+				"datastore_name": ds.Summary.Name,
+				"host_name":      hostNames[mount.Key],
+				"host_id":        mount.Key.Value,
+				"cluster_name":   clusterName,
+			}
+
+			info := mount.MountInfo
+			dsHostMounted.With(labels).Set(
+				boolValue(info.Mounted == nil || *info.Mounted),
+			)
+			dsHostAccessible.With(labels).Set(
+				boolValue(info.Accessible == nil || *info.Accessible),
+			)
+			dsHostReadOnly.With(labels).Set(
+				boolValue(info.AccessMode == string(types.HostMountModeReadOnly)),
+			)
+		}
+	}
+	return nil
+}
+
+// dsMaintenanceModeValue maps the normal/enteringMaintenance/inMaintenance
+// datastore maintenance mode to 0/1/2
+func dsMaintenanceModeValue(mode string) float64 {
+	switch types.DatastoreSummaryMaintenanceModeState(mode) {
+	case types.DatastoreSummaryMaintenanceModeStateEnteringMaintenance:
+		return 1
+	case types.DatastoreSummaryMaintenanceModeStateInMaintenance:
+		return 2
+	}
+	return 0
+}
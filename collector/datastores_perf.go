@@ -0,0 +1,148 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi"
+)
+
+var (
+	datastorePerfLabels  []string = []string{"datastore_name", "host_name", "host_id", "datacenter", "cluster_name"}
+	datastorePerfMetrics          = []perfMetric{
+		{
+			counter: "datastore.totalReadLatency.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "ds",
+					Name:      "read_latency_milliseconds",
+					Help:      "Datastore read latency in milliseconds",
+				},
+				datastorePerfLabels,
+			),
+		},
+		{
+			counter: "datastore.totalWriteLatency.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "ds",
+					Name:      "write_latency_milliseconds",
+					Help:      "Datastore write latency in milliseconds",
+				},
+				datastorePerfLabels,
+			),
+		},
+		{
+			counter: "datastore.numberReadAveraged.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "ds",
+					Name:      "read_iops",
+					Help:      "Datastore read operations per second",
+				},
+				datastorePerfLabels,
+			),
+		},
+		{
+			counter: "datastore.numberWriteAveraged.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "ds",
+					Name:      "write_iops",
+					Help:      "Datastore write operations per second",
+				},
+				datastorePerfLabels,
+			),
+		},
+		{
+			counter: "datastore.read.average",
+			scale:   1024,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "ds",
+					Name:      "read_bytes_per_second",
+					Help:      "Datastore read throughput in bytes per second",
+				},
+				datastorePerfLabels,
+			),
+		},
+		{
+			counter: "datastore.write.average",
+			scale:   1024,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "ds",
+					Name:      "write_bytes_per_second",
+					Help:      "Datastore write throughput in bytes per second",
+				},
+				datastorePerfLabels,
+			),
+		},
+		{
+			counter: "datastore.sizeNormalizedDatastoreLatency.average",
+			scale:   0.001,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "ds",
+					Name:      "normalized_latency_milliseconds",
+					Help:      "Datastore Storage I/O Control size normalized latency in milliseconds",
+				},
+				datastorePerfLabels,
+			),
+		},
+		{
+			counter: "datastore.datastoreIops.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "ds",
+					Name:      "sioc_iops",
+					Help:      "Datastore Storage I/O Control aggregated operations per second",
+				},
+				datastorePerfLabels,
+			),
+		},
+	}
+)
+
+// ExportDatastorePerfMetrics exports datastore I/O metrics as seen by every
+// connected host, datastores have no realtime stats of their own
+func ExportDatastorePerfMetrics(ctx context.Context, c *govmomi.Client) error {
+	entities, err := connectedHostEntities(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	metrics := selectedPerfMetrics("datastore")
+	return exportPerfMetrics(ctx, c.Client, entities, metrics, datastoreInstanceLabels)
+}
+
+// datastoreInstanceLabels resolves the datastore UUID the host datastore
+// counters are instanced by to the datastore name
+func datastoreInstanceLabels(instance string) prometheus.Labels {
+	if instance == "" {
+		return nil
+	}
+
+	datastoreName := DatastoreUUIDMapping[instance]
+	if datastoreName == "" {
+		datastoreName = instance
+	}
+
+	return prometheus.Labels{
+		"datastore_name": datastoreName,
+	}
+}
@@ -0,0 +1,199 @@
+package collector
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	vmVirtualDiskLabels      []string = []string{"machine_name", "host_name", "datacenter", "cluster_name", "disk_key", "disk_label", "datastore_name"}
+	vmVirtualDiskInfoLabels  []string = append(vmVirtualDiskLabels, "file_name", "provisioning", "controller_type", "controller_bus", "unit_number", "disk_mode", "sharing")
+	vmGuestDiskMappingLabels []string = []string{"machine_name", "host_name", "datacenter", "cluster_name", "disk_path", "disk_key"}
+	vmGuestDiskMapping                = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "guest_disk_mapping",
+			Help:      "VM guest filesystem to virtual disk mapping, value is always 1",
+		},
+		vmGuestDiskMappingLabels,
+	)
+	vmVirtualDiskCapacity = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "virtual_disk_capacity_bytes",
+			Help:      "VM virtual disk provisioned capacity in bytes",
+		},
+		vmVirtualDiskLabels,
+	)
+	vmVirtualDiskInfo = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "virtual_disk_info",
+			Help:      "VM virtual disk information, value is always 1",
+		},
+		vmVirtualDiskInfoLabels,
+	)
+	vmVirtualDiskIopsLimit = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "virtual_disk_iops_limit",
+			Help:      "VM virtual disk IOPS limit, -1 means unlimited",
+		},
+		vmVirtualDiskLabels,
+	)
+	vmVirtualDiskIopsReservation = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "virtual_disk_iops_reservation",
+			Help:      "VM virtual disk IOPS reservation",
+		},
+		vmVirtualDiskLabels,
+	)
+	vmVirtualDiskShares = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "virtual_disk_shares",
+			Help:      "VM virtual disk IO shares",
+		},
+		vmVirtualDiskLabels,
+	)
+)
+
+func exportVirtualMachineDiskMetrics(vm mo.VirtualMachine, labels prometheus.Labels) {
+	if vm.Guest != nil {
+		for _, disk := range vm.Guest.Disk {
+			for _, mapping := range disk.Mappings {
+				vmGuestDiskMapping.With(prometheus.Labels{
+					"machine_name": labels["machine_name"],
+					"host_name":    labels["host_name"],
+					"datacenter":   labels["datacenter"],
+					"cluster_name": labels["cluster_name"],
+					"disk_path":    disk.DiskPath,
+					"disk_key":     fmt.Sprintf("%d", mapping.Key),
+				}).Set(1)
+			}
+		}
+	}
+
+	if vm.Config == nil {
+		return
+	}
+
+	devices := object.VirtualDeviceList(vm.Config.Hardware.Device)
+	for _, device := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		disk := device.(*types.VirtualDisk)
+
+		fileName, datastoreName := "", "unknown"
+		if backing, ok := disk.Backing.(types.BaseVirtualDeviceFileBackingInfo); ok {
+			info := backing.GetVirtualDeviceFileBackingInfo()
+			fileName = info.FileName
+			if info.Datastore != nil && DatastoreMapping[info.Datastore.Value] != "" {
+				datastoreName = DatastoreMapping[info.Datastore.Value]
+			}
+		}
+
+		diskLabel := ""
+		if description := disk.DeviceInfo.GetDescription(); description != nil {
+			diskLabel = description.Label
+		}
+
+		diskLabels := prometheus.Labels{
+			"machine_name":   labels["machine_name"],
+			"host_name":      labels["host_name"],
+			"datacenter":     labels["datacenter"],
+			"cluster_name":   labels["cluster_name"],
+			"disk_key":       fmt.Sprintf("%d", disk.Key),
+			"disk_label":     diskLabel,
+			"datastore_name": datastoreName,
+		}
+
+		controllerType, controllerBus := "unknown", ""
+		if controller := devices.FindByKey(disk.ControllerKey); controller != nil {
+			controllerType = devices.Type(controller)
+			if c, ok := controller.(types.BaseVirtualController); ok {
+				controllerBus = fmt.Sprintf("%d", c.GetVirtualController().BusNumber)
+			}
+		}
+
+		unitNumber := ""
+		if disk.UnitNumber != nil {
+			unitNumber = fmt.Sprintf("%d", *disk.UnitNumber)
+		}
+
+		provisioning, diskMode, sharing := diskBackingInfo(disk.Backing)
+
+		infoLabels := prometheus.Labels{
+			"file_name":       fileName,
+			"provisioning":    provisioning,
+			"controller_type": controllerType,
+			"controller_bus":  controllerBus,
+			"unit_number":     unitNumber,
+			"disk_mode":       diskMode,
+			"sharing":         sharing,
+		}
+		for k, v := range diskLabels {
+			infoLabels[k] = v
+		}
+
+		capacity := disk.CapacityInBytes
+		if capacity == 0 {
+			capacity = disk.CapacityInKB * 1024
+		}
+
+		vmVirtualDiskCapacity.With(diskLabels).Set(
+			float64(capacity),
+		)
+		vmVirtualDiskInfo.With(infoLabels).Set(1)
+
+		if allocation := disk.StorageIOAllocation; allocation != nil {
+			if allocation.Limit != nil {
+				vmVirtualDiskIopsLimit.With(diskLabels).Set(
+					float64(*allocation.Limit),
+				)
+			}
+			if allocation.Reservation != nil {
+				vmVirtualDiskIopsReservation.With(diskLabels).Set(
+					float64(*allocation.Reservation),
+				)
+			}
+			if allocation.Shares != nil {
+				vmVirtualDiskShares.With(diskLabels).Set(
+					float64(allocation.Shares.Shares),
+				)
+			}
+		}
+	}
+}
+
+// diskBackingInfo returns the provisioning type, disk mode and sharing mode
+// of a virtual disk backing
+func diskBackingInfo(backing types.BaseVirtualDeviceBackingInfo) (string, string, string) {
+	switch b := backing.(type) {
+	case *types.VirtualDiskFlatVer2BackingInfo:
+		provisioning := "lazyZeroedThick"
+		if b.ThinProvisioned != nil && *b.ThinProvisioned {
+			provisioning = "thin"
+		} else if b.EagerlyScrub != nil && *b.EagerlyScrub {
+			provisioning = "eagerZeroedThick"
+		}
+		return provisioning, b.DiskMode, b.Sharing
+	case *types.VirtualDiskSeSparseBackingInfo:
+		return "thin", b.DiskMode, ""
+	case *types.VirtualDiskSparseVer2BackingInfo:
+		return "thin", b.DiskMode, ""
+	case *types.VirtualDiskRawDiskMappingVer1BackingInfo:
+		return "rdm", b.DiskMode, b.Sharing
+	}
+	return "unknown", "", ""
+}
@@ -0,0 +1,328 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/event"
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// EventTypes are the event types counted by vmware_events_total, "*" counts
+// every event type. EventEx and ExtendedEvent events are matched by their
+// event type id, all others by their type name.
+var EventTypes = []string{
+	"VmMigratedEvent",
+	"DrsVmMigratedEvent",
+	"VmFailoverFailed",
+	"com.vmware.vc.ha.VmRestartedByHAEvent",
+	"com.vmware.vc.HA.DasHostFailedEvent",
+	"HostConnectionLostEvent",
+	"HostDisconnectedEvent",
+	"BadUsernameSessionEvent",
+}
+
+// EventsCheckpointFile persists the event and task cursor, so no event is
+// counted or logged twice across restarts
+var EventsCheckpointFile = ""
+
+// eventPageSize is the number of events or tasks read per history page
+const eventPageSize = 1000
+
+var (
+	eventsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "vmware",
+			Subsystem: "events",
+			Name:      "total",
+			Help:      "Number of vCenter events by type",
+		},
+		[]string{"type"},
+	)
+	taskErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "vmware",
+			Subsystem: "tasks",
+			Name:      "errors_total",
+			Help:      "Number of vCenter tasks which completed with an error by task",
+		},
+		[]string{"task"},
+	)
+
+	// cursor is nil until the first run, which loads the checkpoint or
+	// starts at the current vCenter time
+	cursor *eventCursor
+)
+
+// eventCursor is the position in the event and task history
+type eventCursor struct {
+	EventKey  int32     `json:"event_key"`
+	EventTime time.Time `json:"event_time"`
+	TaskTime  time.Time `json:"task_time"`
+	// TaskKeys are the tasks completed at TaskTime which were processed
+	TaskKeys []string `json:"task_keys"`
+}
+
+func ExportEventMetrics(ctx context.Context, client *govmomi.Client) error {
+
+	c := client.Client
+
+	if cursor == nil {
+		loaded, err := loadEventCursor(ctx, c)
+		if err != nil {
+			log.Printf("Error loading event checkpoint: %v", err)
+			return err
+		}
+		cursor = loaded
+
+		// Export the configured types before their first event
+		for _, eventType := range EventTypes {
+			if eventType != "*" {
+				eventsTotal.WithLabelValues(eventType).Add(0)
+			}
+		}
+	}
+
+	events, err := tailEvents(ctx, c, cursor)
+	if err != nil {
+		log.Printf("Error reading events: %v", err)
+		return err
+	}
+
+	logEvents(events)
+
+	counted := make(map[string]bool)
+	for _, eventType := range EventTypes {
+		counted[eventType] = true
+	}
+	for _, e := range events {
+		eventType := eventTypeName(e)
+		if counted["*"] || counted[eventType] {
+			eventsTotal.WithLabelValues(eventType).Inc()
+		}
+	}
+
+	tasks, err := tailTasks(ctx, c, cursor)
+	if err != nil {
+		log.Printf("Error reading tasks: %v", err)
+		// Keep the events counted so far
+		saveEventCursor(cursor)
+		return err
+	}
+	logTasks(tasks)
+
+	for _, info := range tasks {
+		if info.State == types.TaskInfoStateError {
+			taskErrorsTotal.WithLabelValues(info.DescriptionId).Inc()
+		}
+	}
+
+	return saveEventCursor(cursor)
+}
+
+// tailEvents returns the events newer than the cursor, oldest first, and
+// moves the cursor past them
+func tailEvents(ctx context.Context, c *vim25.Client, cursor *eventCursor) ([]types.BaseEvent, error) {
+	m := event.NewManager(c)
+
+	begin := cursor.EventTime
+	collector, err := m.CreateCollectorForEvents(ctx, types.EventFilterSpec{
+		Time: &types.EventFilterSpecByTime{
+			BeginTime: &begin,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer collector.Destroy(ctx)
+
+	if err = collector.Rewind(ctx); err != nil {
+		return nil, err
+	}
+
+	var events []types.BaseEvent
+	for {
+		page, err := collector.ReadNextEvents(ctx, eventPageSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		for _, e := range page {
+			// The begin time is inclusive, event keys only grow
+			if e.GetEvent().Key <= cursor.EventKey {
+				continue
+			}
+			events = append(events, e)
+		}
+	}
+
+	event.Sort(events)
+	for _, e := range events {
+		if e.GetEvent().Key > cursor.EventKey {
+			cursor.EventKey = e.GetEvent().Key
+		}
+		if e.GetEvent().CreatedTime.After(cursor.EventTime) {
+			cursor.EventTime = e.GetEvent().CreatedTime
+		}
+	}
+	return events, nil
+}
+
+// tailTasks returns the tasks which completed after the cursor and moves the
+// cursor past them
+func tailTasks(ctx context.Context, c *vim25.Client, cursor *eventCursor) ([]types.TaskInfo, error) {
+	m := task.NewManager(c)
+
+	begin := cursor.TaskTime
+	collector, err := m.CreateCollectorForTasks(ctx, types.TaskFilterSpec{
+		Time: &types.TaskFilterSpecByTime{
+			TimeType:  types.TaskFilterSpecTimeOptionCompletedTime,
+			BeginTime: &begin,
+		},
+		State: []types.TaskInfoState{types.TaskInfoStateSuccess, types.TaskInfoStateError},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer collector.Destroy(ctx)
+
+	if err = collector.Rewind(ctx); err != nil {
+		return nil, err
+	}
+
+	processed := make(map[string]bool)
+	for _, key := range cursor.TaskKeys {
+		processed[key] = true
+	}
+
+	var tasks []types.TaskInfo
+	for {
+		page, err := collector.ReadNextTasks(ctx, eventPageSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		for _, info := range page {
+			if info.CompleteTime == nil || processed[info.Key] || info.CompleteTime.Before(cursor.TaskTime) {
+				continue
+			}
+			processed[info.Key] = true
+			tasks = append(tasks, info)
+		}
+	}
+
+	for _, info := range tasks {
+		if info.CompleteTime.After(cursor.TaskTime) {
+			cursor.TaskTime = *info.CompleteTime
+			cursor.TaskKeys = nil
+		}
+	}
+	for _, info := range tasks {
+		if info.CompleteTime.Equal(cursor.TaskTime) {
+			cursor.TaskKeys = append(cursor.TaskKeys, info.Key)
+		}
+	}
+	return tasks, nil
+}
+
+// eventTypeName returns the event type id of EventEx and ExtendedEvent
+// events and the type name of all others
+func eventTypeName(e types.BaseEvent) string {
+	switch e := e.(type) {
+	case *types.EventEx:
+		return e.EventTypeId
+	case *types.ExtendedEvent:
+		return e.EventTypeId
+	}
+	return reflect.TypeOf(e).Elem().Name()
+}
+
+// loadEventCursor reads the checkpoint file, without one the cursor starts at
+// the current vCenter time
+func loadEventCursor(ctx context.Context, c *vim25.Client) (*eventCursor, error) {
+	if EventsCheckpointFile != "" {
+		data, err := os.ReadFile(EventsCheckpointFile)
+		if err == nil {
+			var loaded eventCursor
+			if err = json.Unmarshal(data, &loaded); err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", EventsCheckpointFile, err)
+			}
+			return &loaded, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	now, err := methods.GetCurrentTime(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	// The begin time is inclusive, skip the events which already exist
+	latest, err := latestEventKey(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventCursor{
+		EventKey:  latest,
+		EventTime: *now,
+		TaskTime:  *now,
+	}, nil
+}
+
+// latestEventKey returns the key of the newest event
+func latestEventKey(ctx context.Context, c *vim25.Client) (int32, error) {
+	m := event.NewManager(c)
+
+	events, err := m.QueryEvents(ctx, types.EventFilterSpec{
+		MaxCount: 1,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var key int32
+	for _, e := range events {
+		if e.GetEvent().Key > key {
+			key = e.GetEvent().Key
+		}
+	}
+	return key, nil
+}
+
+// saveEventCursor writes the checkpoint file, replacing it atomically
+func saveEventCursor(cursor *eventCursor) error {
+	if EventsCheckpointFile == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+
+	tmp := EventsCheckpointFile + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, EventsCheckpointFile)
+}
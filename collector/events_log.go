@@ -0,0 +1,107 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// EventsLog receives every event and completed task as a JSON line, nil
+// disables the log
+var EventsLog io.Writer
+
+// eventRecord is a logged event or task
+type eventRecord struct {
+	Kind       string     `json:"kind"`
+	Type       string     `json:"type"`
+	Key        string     `json:"key"`
+	Time       time.Time  `json:"time"`
+	User       string     `json:"user,omitempty"`
+	EntityPath string     `json:"entity_path,omitempty"`
+	Message    string     `json:"message,omitempty"`
+	State      string     `json:"state,omitempty"`
+	StartTime  *time.Time `json:"start_time,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+func logEvents(events []types.BaseEvent) {
+	for _, e := range events {
+		event := e.GetEvent()
+		writeEventRecord(eventRecord{
+			Kind:       "event",
+			Type:       eventTypeName(e),
+			Key:        fmt.Sprintf("%d", event.Key),
+			Time:       event.CreatedTime,
+			User:       event.UserName,
+			EntityPath: eventEntityPath(event),
+			Message:    event.FullFormattedMessage,
+		})
+	}
+}
+
+func logTasks(tasks []types.TaskInfo) {
+	for _, info := range tasks {
+		record := eventRecord{
+			Kind:       "task",
+			Type:       info.DescriptionId,
+			Key:        info.Key,
+			Time:       *info.CompleteTime,
+			EntityPath: info.EntityName,
+			State:      string(info.State),
+			StartTime:  info.StartTime,
+		}
+		if reason, ok := info.Reason.(*types.TaskReasonUser); ok {
+			record.User = reason.UserName
+		}
+		if info.Error != nil {
+			record.Error = info.Error.LocalizedMessage
+		}
+		writeEventRecord(record)
+	}
+}
+
+func writeEventRecord(record eventRecord) {
+	if EventsLog == nil {
+		return
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.Printf("Error encoding %s %s: %v", record.Kind, record.Key, err)
+		return
+	}
+	if _, err = EventsLog.Write(append(data, '\n')); err != nil {
+		log.Printf("Error writing %s %s: %v", record.Kind, record.Key, err)
+	}
+}
+
+// eventEntityPath joins the datacenter, compute resource and host an event
+// refers to, followed by its VM, datastore, network or switch
+func eventEntityPath(event *types.Event) string {
+	var names []string
+	if event.Datacenter != nil {
+		names = append(names, event.Datacenter.Name)
+	}
+	if event.ComputeResource != nil {
+		names = append(names, event.ComputeResource.Name)
+	}
+	if event.Host != nil && (event.ComputeResource == nil || event.Host.Name != event.ComputeResource.Name) {
+		names = append(names, event.Host.Name)
+	}
+	switch {
+	case event.Vm != nil:
+		names = append(names, event.Vm.Name)
+	case event.Ds != nil:
+		names = append(names, event.Ds.Name)
+	case event.Net != nil:
+		names = append(names, event.Net.Name)
+	case event.Dvs != nil:
+		names = append(names, event.Dvs.Name)
+	}
+	return strings.Join(names, "/")
+}
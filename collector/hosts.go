@@ -2,13 +2,14 @@ package collector
 
 import (
 	"context"
-	"fmt"
+	"log"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/view"
 	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 var (
@@ -49,6 +50,15 @@ var (
 		},
 		hostLabels,
 	)
+	hostConnectionState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "connection_state",
+			Help:      "Host connection state (0 = disconnected, 1 = connected, 2 = notResponding)",
+		},
+		hostLabels,
+	)
 	hostCpuCores = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "vmware",
@@ -103,6 +113,15 @@ var (
 		},
 		hostLabels,
 	)
+	hostMaintenanceMode = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "maintenance_mode",
+			Help:      "Host is in maintenance mode (1 = true, 0 = false)",
+		},
+		hostLabels,
+	)
 	hostMemoryAllocRes = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "vmware",
@@ -157,6 +176,42 @@ var (
 		},
 		hostLabels,
 	)
+	hostOverallStatus = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "overall_status",
+			Help:      "Host overall status (0 = green, 1 = yellow, 2 = red, 3 = gray)",
+		},
+		hostLabels,
+	)
+	hostPowerState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "power_state",
+			Help:      "Host power state (0 = poweredOff, 1 = poweredOn, 2 = standBy, 3 = unknown)",
+		},
+		hostLabels,
+	)
+	hostRebootRequired = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "reboot_required",
+			Help:      "Host needs a reboot (1 = true, 0 = false)",
+		},
+		hostLabels,
+	)
+	hostStandbyMode = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "standby_mode",
+			Help:      "Host standby mode (0 = none, 1 = entering, 2 = in, 3 = exiting)",
+		},
+		hostLabels,
+	)
 	hostUptime = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "vmware",
@@ -168,6 +223,28 @@ var (
 	)
 )
 
+// hostResourceMetrics are the metrics sourced from the host config and
+// quickstats, only valid while the host is connected
+var hostResourceMetrics = []*prometheus.GaugeVec{
+	hostAvailPMem,
+	hostCpuAllocRes,
+	hostCpuAllocLim,
+	hostCpuAllocOver,
+	hostCpuCores,
+	hostCpuFree,
+	hostCpuOverallUsage,
+	hostCpuMhz,
+	hostCpuTotal,
+	hostCpuThreads,
+	hostMemoryAllocRes,
+	hostMemoryAllocLim,
+	hostMemoryFree,
+	hostMemorySize,
+	hostMemoryOverallUsage,
+	hostNicsNum,
+	hostUptime,
+}
+
 func ExportHostMetrics(ctx context.Context, c *govmomi.Client) error {
 	// Create a view manager
 	m := view.NewManager(c.Client)
@@ -175,7 +252,8 @@ func ExportHostMetrics(ctx context.Context, c *govmomi.Client) error {
 	// Create a container view for the datacenters
 	containerView, err := m.CreateContainerView(ctx, c.ServiceContent.RootFolder, []string{"Datacenter"}, true)
 	if err != nil {
-		fmt.Printf("Error creating container view: %v\n", err)
+		log.Printf("Error creating container view: %v", err)
+		return err
 	}
 	defer containerView.Destroy(ctx)
 
@@ -183,28 +261,50 @@ func ExportHostMetrics(ctx context.Context, c *govmomi.Client) error {
 	var datacenters []mo.Datacenter
 	err = containerView.Retrieve(ctx, []string{"Datacenter"}, nil, &datacenters)
 	if err != nil {
-		fmt.Printf("Error retrieving datacenters: %v\n", err)
+		log.Printf("Error retrieving datacenters: %v", err)
+		return err
 	}
 
-	// Iterate through the datacenters and list hosts
-	for _, dc := range datacenters {
+	// Retrieve the hosts of every datacenter before touching any series, so a
+	// failed retrieval does not leave the NIC, adapter and info series empty
+	hostsByDatacenter := make([][]mo.HostSystem, len(datacenters))
+	for i, dc := range datacenters {
 
 		// Create a container view for the hosts in the datacenter
 		containerView, err := m.CreateContainerView(ctx, dc.Reference(), []string{"HostSystem"}, true)
 		if err != nil {
-			fmt.Printf("Error creating container view for hosts: %v\n", err)
+			log.Printf("Error creating container view for hosts: %v", err)
+			return err
 		}
 		defer containerView.Destroy(ctx)
 
 		// Retrieve a list of hosts in the datacenter
-		var hosts []mo.HostSystem
-
-		err = containerView.Retrieve(ctx, []string{"HostSystem"}, nil, &hosts)
+		err = containerView.Retrieve(ctx, []string{"HostSystem"}, nil, &hostsByDatacenter[i])
 		if err != nil {
-			fmt.Printf("Error retrieving hosts: %v\n", err)
+			log.Printf("Error retrieving hosts: %v", err)
+			return err
 		}
+	}
 
-		for _, host := range hosts {
+	// Removed vSwitches, portgroups, NICs, adapters and LUNs must disappear,
+	// as must the info of upgraded hosts and the sensors of hosts which got
+	// disconnected
+	for _, gauge := range hostHealthMetrics {
+		gauge.Reset()
+	}
+	for _, gauge := range hostNetworkMetrics {
+		gauge.Reset()
+	}
+	for _, gauge := range hostStorageMetrics {
+		gauge.Reset()
+	}
+	for _, gauge := range hostInfoMetrics {
+		gauge.Reset()
+	}
+
+	// Iterate through the datacenters and their hosts
+	for i, dc := range datacenters {
+		for _, host := range hostsByDatacenter[i] {
 
 			hostID := host.Self.Reference().Value
 			populateVirtualMachineMapping(hostID, host.Name)
@@ -223,6 +323,39 @@ func ExportHostMetrics(ctx context.Context, c *govmomi.Client) error {
 				"cluster_name": clusterName,
 			}
 
+			hostConnectionState.With(labels).Set(
+				hostConnectionStateValue(host.Runtime.ConnectionState),
+			)
+			hostMaintenanceMode.With(labels).Set(
+				boolValue(host.Runtime.InMaintenanceMode),
+			)
+			hostOverallStatus.With(labels).Set(
+				managedEntityStatusValue(host.Summary.OverallStatus),
+			)
+			hostPowerState.With(labels).Set(
+				hostPowerStateValue(host.Runtime.PowerState),
+			)
+			hostRebootRequired.With(labels).Set(
+				boolValue(host.Summary.RebootRequired),
+			)
+			hostStandbyMode.With(labels).Set(
+				hostStandbyModeValue(host.Runtime.StandbyMode),
+			)
+
+			// Disconnected hosts have no config and only stale quickstats,
+			// drop their resource series instead of exporting the last values
+			if host.Runtime.ConnectionState != types.HostSystemConnectionStateConnected || host.Config == nil {
+				for _, gauge := range hostResourceMetrics {
+					gauge.Delete(labels)
+				}
+				continue
+			}
+
+			exportHostHealthMetrics(host, labels)
+			exportHostNetworkMetrics(host, labels)
+			exportHostStorageMetrics(host, labels)
+			exportHostInfoMetrics(host, labels)
+
 			cpuTotal := int64(host.Summary.Hardware.CpuMhz) * int64(host.Summary.Hardware.NumCpuCores) * int64(host.Summary.Hardware.NumCpuThreads)
 
 			hostAvailPMem.With(labels).Set(
@@ -280,3 +413,37 @@ func ExportHostMetrics(ctx context.Context, c *govmomi.Client) error {
 	}
 	return nil
 }
+
+func hostConnectionStateValue(state types.HostSystemConnectionState) float64 {
+	switch state {
+	case types.HostSystemConnectionStateConnected:
+		return 1
+	case types.HostSystemConnectionStateNotResponding:
+		return 2
+	}
+	return 0
+}
+
+func hostPowerStateValue(state types.HostSystemPowerState) float64 {
+	switch state {
+	case types.HostSystemPowerStatePoweredOff:
+		return 0
+	case types.HostSystemPowerStatePoweredOn:
+		return 1
+	case types.HostSystemPowerStateStandBy:
+		return 2
+	}
+	return 3
+}
+
+func hostStandbyModeValue(mode string) float64 {
+	switch mode {
+	case "entering":
+		return 1
+	case "in":
+		return 2
+	case "exiting":
+		return 3
+	}
+	return 0
+}
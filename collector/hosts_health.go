@@ -0,0 +1,124 @@
+package collector
+
+import (
+	"math"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	hostSensorLabels         []string = []string{"host_name", "host_id", "datacenter", "cluster_name", "sensor_name", "sensor_type", "unit"}
+	hostHardwareStatusLabels []string = []string{"host_name", "host_id", "datacenter", "cluster_name", "component", "element_name"}
+	hostHardwareStatus                = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "hardware_status",
+			Help:      "Host hardware element status (0 = green, 1 = yellow, 2 = red, 3 = unknown)",
+		},
+		hostHardwareStatusLabels,
+	)
+	hostSensorHealth = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "sensor_health_state",
+			Help:      "Host sensor health state (0 = green, 1 = yellow, 2 = red, 3 = unknown)",
+		},
+		hostSensorLabels,
+	)
+	hostSensorReading = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "sensor_reading",
+			Help:      "Host sensor reading in the unit of the unit label",
+		},
+		hostSensorLabels,
+	)
+
+	hostHealthMetrics = []*prometheus.GaugeVec{
+		hostHardwareStatus, hostSensorHealth, hostSensorReading,
+	}
+)
+
+func exportHostHealthMetrics(host mo.HostSystem, labels prometheus.Labels) {
+	if host.Runtime.HealthSystemRuntime == nil {
+		return
+	}
+	health := host.Runtime.HealthSystemRuntime
+
+	if health.SystemHealthInfo != nil {
+		for _, sensor := range health.SystemHealthInfo.NumericSensorInfo {
+			unit := sensor.BaseUnits
+			if sensor.RateUnits != "" && sensor.RateUnits != "none" {
+				unit += "/" + sensor.RateUnits
+			}
+
+			sensorLabels := prometheus.Labels{
+				"sensor_name": sensor.Name,
+				"sensor_type": sensor.SensorType,
+				"unit":        unit,
+			}
+			for k, v := range labels {
+				sensorLabels[k] = v
+			}
+
+			hostSensorReading.With(sensorLabels).Set(
+				float64(sensor.CurrentReading) * math.Pow10(int(sensor.UnitModifier)),
+			)
+			hostSensorHealth.With(sensorLabels).Set(
+				healthStateValue(sensor.HealthState),
+			)
+		}
+	}
+
+	if status := health.HardwareStatusInfo; status != nil {
+		elements := map[string][]types.BaseHostHardwareElementInfo{
+			"memory": status.MemoryStatusInfo,
+			"cpu":    status.CpuStatusInfo,
+		}
+		for i := range status.StorageStatusInfo {
+			elements["storage"] = append(elements["storage"], &status.StorageStatusInfo[i])
+		}
+
+		for component, infos := range elements {
+			for _, info := range infos {
+				element := info.GetHostHardwareElementInfo()
+
+				elementLabels := prometheus.Labels{
+					"component":    component,
+					"element_name": element.Name,
+				}
+				for k, v := range labels {
+					elementLabels[k] = v
+				}
+
+				hostHardwareStatus.With(elementLabels).Set(
+					healthStateValue(element.Status),
+				)
+			}
+		}
+	}
+}
+
+// healthStateValue maps the green/yellow/red/unknown health state keys to
+// 0/1/2/3
+func healthStateValue(state types.BaseElementDescription) float64 {
+	if state == nil {
+		return 3
+	}
+	switch strings.ToLower(state.GetElementDescription().Key) {
+	case "green":
+		return 0
+	case "yellow":
+		return 1
+	case "red":
+		return 2
+	}
+	return 3
+}
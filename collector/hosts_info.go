@@ -0,0 +1,94 @@
+package collector
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi/vim25/mo"
+)
+
+var (
+	hostInfoLabels []string = []string{"host_name", "host_id", "datacenter", "cluster_name", "version", "build", "vendor", "model", "bios_version", "serial_number"}
+	hostInfo                = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "info",
+			Help:      "Host ESXi version and hardware, value is always 1",
+		},
+		hostInfoLabels,
+	)
+	hostCertificateExpiry = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "certificate_expiry_timestamp_seconds",
+			Help:      "Host certificate expiry time in seconds since epoch",
+		},
+		hostLabels,
+	)
+
+	hostInfoMetrics = []*prometheus.GaugeVec{
+		hostInfo, hostCertificateExpiry,
+	}
+)
+
+func exportHostInfoMetrics(host mo.HostSystem, labels prometheus.Labels) {
+	infoLabels := prometheus.Labels{
+		"version":       host.Config.Product.Version,
+		"build":         host.Config.Product.Build,
+		"vendor":        "",
+		"model":         "",
+		"bios_version":  "",
+		"serial_number": "",
+	}
+	for k, v := range labels {
+		infoLabels[k] = v
+	}
+
+	if hardware := host.Hardware; hardware != nil {
+		infoLabels["vendor"] = hardware.SystemInfo.Vendor
+		infoLabels["model"] = hardware.SystemInfo.Model
+		infoLabels["serial_number"] = hostSerialNumber(host)
+		if hardware.BiosInfo != nil {
+			infoLabels["bios_version"] = hardware.BiosInfo.BiosVersion
+		}
+	}
+	hostInfo.With(infoLabels).Set(1)
+
+	if cert := parseHostCertificate(host.Config.Certificate); cert != nil {
+		hostCertificateExpiry.With(labels).Set(
+			float64(cert.NotAfter.Unix()),
+		)
+	}
+}
+
+// hostSerialNumber returns the system serial number, hosts before 6.7 only
+// report it among the other identifying info
+func hostSerialNumber(host mo.HostSystem) string {
+	if host.Hardware.SystemInfo.SerialNumber != "" {
+		return host.Hardware.SystemInfo.SerialNumber
+	}
+	for _, key := range []string{"SerialNumberTag", "ServiceTag"} {
+		for _, info := range host.Hardware.SystemInfo.OtherIdentifyingInfo {
+			if info.IdentifierType != nil && info.IdentifierType.GetElementDescription().Key == key {
+				return info.IdentifierValue
+			}
+		}
+	}
+	return ""
+}
+
+// parseHostCertificate parses the PEM or DER encoded host certificate
+func parseHostCertificate(data []byte) *x509.Certificate {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	cert, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil
+	}
+	return cert
+}
@@ -0,0 +1,194 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi/vim25/mo"
+)
+
+var (
+	hostVswitchLabels   []string = []string{"host_name", "host_id", "datacenter", "cluster_name", "vswitch_name"}
+	hostPortgroupLabels []string = []string{"host_name", "host_id", "datacenter", "cluster_name", "vswitch_name", "portgroup_name"}
+	hostPnicLabels      []string = []string{"host_name", "host_id", "datacenter", "cluster_name", "nic"}
+	hostPnicInfoLabels  []string = []string{"host_name", "host_id", "datacenter", "cluster_name", "nic", "driver", "mac", "switch_name", "switch_type"}
+	hostVswitchMtu               = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "vswitch_mtu_bytes",
+			Help:      "Host standard vSwitch MTU in bytes",
+		},
+		hostVswitchLabels,
+	)
+	hostVswitchPorts = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "vswitch_ports_total",
+			Help:      "Host standard vSwitch ports",
+		},
+		hostVswitchLabels,
+	)
+	hostVswitchPortsAvailable = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "vswitch_ports_available",
+			Help:      "Host standard vSwitch ports available",
+		},
+		hostVswitchLabels,
+	)
+	hostPortgroupVlan = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "portgroup_vlan_id",
+			Help:      "Host standard portgroup VLAN ID, 0 is untagged and 4095 trunks all VLANs",
+		},
+		hostPortgroupLabels,
+	)
+	hostPortgroupPortsInUse = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "portgroup_ports_in_use",
+			Help:      "Host standard portgroup ports connected to a VM or VMkernel NIC",
+		},
+		hostPortgroupLabels,
+	)
+	hostPnicInfo = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "nic_info",
+			Help:      "Host physical NIC driver, MAC address and the switch it is an uplink of, value is always 1",
+		},
+		hostPnicInfoLabels,
+	)
+	hostPnicLinkUp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "nic_link_up",
+			Help:      "Host physical NIC link state (1 = up, 0 = down)",
+		},
+		hostPnicLabels,
+	)
+	hostPnicLinkSpeed = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "nic_link_speed_mbps",
+			Help:      "Host physical NIC negotiated link speed in Mbit/s",
+		},
+		hostPnicLabels,
+	)
+	hostPnicFullDuplex = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "nic_full_duplex",
+			Help:      "Host physical NIC negotiated duplex (1 = full, 0 = half)",
+		},
+		hostPnicLabels,
+	)
+
+	hostNetworkMetrics = []*prometheus.GaugeVec{
+		hostVswitchMtu, hostVswitchPorts, hostVswitchPortsAvailable, hostPortgroupVlan, hostPortgroupPortsInUse,
+		hostPnicInfo, hostPnicLinkUp, hostPnicLinkSpeed, hostPnicFullDuplex,
+	}
+)
+
+func exportHostNetworkMetrics(host mo.HostSystem, labels prometheus.Labels) {
+	if host.Config == nil || host.Config.Network == nil {
+		return
+	}
+	network := host.Config.Network
+
+	for _, vswitch := range network.Vswitch {
+		vswitchLabels := prometheus.Labels{
+			"vswitch_name": vswitch.Name,
+		}
+		for k, v := range labels {
+			vswitchLabels[k] = v
+		}
+
+		if vswitch.Mtu > 0 {
+			hostVswitchMtu.With(vswitchLabels).Set(
+				float64(vswitch.Mtu),
+			)
+		}
+		hostVswitchPorts.With(vswitchLabels).Set(
+			float64(vswitch.NumPorts),
+		)
+		hostVswitchPortsAvailable.With(vswitchLabels).Set(
+			float64(vswitch.NumPortsAvailable),
+		)
+	}
+
+	for _, portgroup := range network.Portgroup {
+		portgroupLabels := prometheus.Labels{
+			"vswitch_name":   portgroup.Spec.VswitchName,
+			"portgroup_name": portgroup.Spec.Name,
+		}
+		for k, v := range labels {
+			portgroupLabels[k] = v
+		}
+
+		hostPortgroupVlan.With(portgroupLabels).Set(
+			float64(portgroup.Spec.VlanId),
+		)
+		hostPortgroupPortsInUse.With(portgroupLabels).Set(
+			float64(len(portgroup.Port)),
+		)
+	}
+
+	// The switch every physical NIC is an uplink of, by NIC key
+	switchNames := make(map[string]string)
+	switchTypes := make(map[string]string)
+	for _, vswitch := range network.Vswitch {
+		for _, key := range vswitch.Pnic {
+			switchNames[key] = vswitch.Name
+			switchTypes[key] = "vswitch"
+		}
+	}
+	for _, proxy := range network.ProxySwitch {
+		for _, key := range proxy.Pnic {
+			switchNames[key] = proxy.DvsName
+			switchTypes[key] = "dvs"
+		}
+	}
+
+	for _, pnic := range network.Pnic {
+		pnicLabels := prometheus.Labels{
+			"nic": pnic.Device,
+		}
+		for k, v := range labels {
+			pnicLabels[k] = v
+		}
+
+		infoLabels := prometheus.Labels{
+			"driver":      pnic.Driver,
+			"mac":         pnic.Mac,
+			"switch_name": switchNames[pnic.Key],
+			"switch_type": switchTypes[pnic.Key],
+		}
+		for k, v := range pnicLabels {
+			infoLabels[k] = v
+		}
+		hostPnicInfo.With(infoLabels).Set(1)
+
+		// A NIC without a link has no link speed
+		hostPnicLinkUp.With(pnicLabels).Set(
+			boolValue(pnic.LinkSpeed != nil),
+		)
+		if pnic.LinkSpeed != nil {
+			hostPnicLinkSpeed.With(pnicLabels).Set(
+				float64(pnic.LinkSpeed.SpeedMb),
+			)
+			hostPnicFullDuplex.With(pnicLabels).Set(
+				boolValue(pnic.LinkSpeed.Duplex),
+			)
+		}
+	}
+}
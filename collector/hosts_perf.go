@@ -0,0 +1,352 @@
+package collector
+
+import (
+	"context"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	hostPerfLabels  []string = []string{"host_name", "host_id", "datacenter", "cluster_name", "counter_instance"}
+	hostPerfMetrics          = []perfMetric{
+		{
+			counter: "net.received.average",
+			scale:   1024,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "net_received_bytes_per_second",
+					Help:      "Host physical NIC receive throughput in bytes per second",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "net.transmitted.average",
+			scale:   1024,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "net_transmitted_bytes_per_second",
+					Help:      "Host physical NIC transmit throughput in bytes per second",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "net.errorsRx.summation",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "net_errors_rx_packets",
+					Help:      "Host physical NIC receive errors over the 20s sample",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "net.errorsTx.summation",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "net_errors_tx_packets",
+					Help:      "Host physical NIC transmit errors over the 20s sample",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "net.droppedRx.summation",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "net_dropped_rx_packets",
+					Help:      "Host physical NIC received packets dropped over the 20s sample",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "net.droppedTx.summation",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "net_dropped_tx_packets",
+					Help:      "Host physical NIC transmitted packets dropped over the 20s sample",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "storageAdapter.totalReadLatency.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "storage_adapter_read_latency_milliseconds",
+					Help:      "Host storage adapter read latency in milliseconds",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "storageAdapter.totalWriteLatency.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "storage_adapter_write_latency_milliseconds",
+					Help:      "Host storage adapter write latency in milliseconds",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "storageAdapter.numberReadAveraged.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "storage_adapter_read_iops",
+					Help:      "Host storage adapter read operations per second",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "storageAdapter.numberWriteAveraged.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "storage_adapter_write_iops",
+					Help:      "Host storage adapter write operations per second",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "disk.totalReadLatency.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "disk_read_latency_milliseconds",
+					Help:      "Host LUN read latency in milliseconds",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "disk.totalWriteLatency.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "disk_write_latency_milliseconds",
+					Help:      "Host LUN write latency in milliseconds",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "disk.numberReadAveraged.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "disk_read_iops",
+					Help:      "Host LUN read operations per second",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "disk.numberWriteAveraged.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "disk_write_iops",
+					Help:      "Host LUN write operations per second",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "cpu.ready.summation",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "cpu_ready_milliseconds",
+					Help:      "Host CPU ready time in milliseconds over the 20s sample",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "cpu.utilization.average",
+			scale:   0.01,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "cpu_utilization_percent",
+					Help:      "Host CPU utilization in percent",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "mem.swapinRate.average",
+			scale:   1024,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "memory_swap_in_bytes_per_second",
+					Help:      "Host memory swap in rate in bytes per second",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "mem.swapoutRate.average",
+			scale:   1024,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "memory_swap_out_bytes_per_second",
+					Help:      "Host memory swap out rate in bytes per second",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "mem.compressionRate.average",
+			scale:   1024,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "memory_compression_bytes_per_second",
+					Help:      "Host memory compression rate in bytes per second",
+				},
+				hostPerfLabels,
+			),
+		},
+		{
+			counter: "mem.decompressionRate.average",
+			scale:   1024,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "host",
+					Name:      "memory_decompression_bytes_per_second",
+					Help:      "Host memory decompression rate in bytes per second",
+				},
+				hostPerfLabels,
+			),
+		},
+	}
+)
+
+func ExportHostPerfMetrics(ctx context.Context, c *govmomi.Client) error {
+	entities, err := connectedHostEntities(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	metrics := selectedPerfMetrics("host")
+	return exportPerfMetrics(ctx, c.Client, entities, metrics, perfInstanceLabels)
+}
+
+// connectedHostEntities returns the labels of every connected host, realtime
+// stats are only available for those
+func connectedHostEntities(ctx context.Context, c *govmomi.Client) (map[types.ManagedObjectReference]prometheus.Labels, error) {
+	// Create a view manager
+	m := view.NewManager(c.Client)
+
+	// Create a container view for the datacenters
+	containerView, err := m.CreateContainerView(ctx, c.ServiceContent.RootFolder, []string{"Datacenter"}, true)
+	if err != nil {
+		log.Printf("Error creating container view: %v", err)
+		return nil, err
+	}
+	defer containerView.Destroy(ctx)
+
+	// Retrieve a list of datacenters
+	var datacenters []mo.Datacenter
+	err = containerView.Retrieve(ctx, []string{"Datacenter"}, []string{"name"}, &datacenters)
+	if err != nil {
+		log.Printf("Error retrieving datacenters: %v", err)
+		return nil, err
+	}
+
+	entities := make(map[types.ManagedObjectReference]prometheus.Labels)
+	for _, dc := range datacenters {
+
+		// Create a container view for the hosts in the datacenter
+		containerView, err := m.CreateContainerView(ctx, dc.Reference(), []string{"HostSystem"}, true)
+		if err != nil {
+			log.Printf("Error creating container view for hosts: %v", err)
+			return nil, err
+		}
+		defer containerView.Destroy(ctx)
+
+		var hosts []mo.HostSystem
+		err = containerView.Retrieve(ctx, []string{"HostSystem"}, []string{"name", "runtime"}, &hosts)
+		if err != nil {
+			log.Printf("Error retrieving hosts: %v", err)
+			return nil, err
+		}
+
+		for _, host := range hosts {
+			if host.Runtime.ConnectionState != types.HostSystemConnectionStateConnected {
+				continue
+			}
+			hostID := host.Self.Value
+
+			clusterName := HostMapping[hostID]
+			if clusterName == "" {
+				clusterName = "none"
+			}
+
+			entities[host.Self] = prometheus.Labels{
+				"host_name":    host.Name,
+				"host_id":      hostID,
+				"datacenter":   dc.Name,
+				"cluster_name": clusterName,
+			}
+		}
+	}
+
+	return entities, nil
+}
@@ -0,0 +1,196 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	hostHbaLabels      []string = []string{"host_name", "host_id", "datacenter", "cluster_name", "hba"}
+	hostHbaInfoLabels  []string = []string{"host_name", "host_id", "datacenter", "cluster_name", "hba", "hba_type", "model", "driver", "status"}
+	hostLunLabels      []string = []string{"host_name", "host_id", "datacenter", "cluster_name", "lun"}
+	hostLunInfoLabels  []string = []string{"host_name", "host_id", "datacenter", "cluster_name", "lun", "display_name", "lun_type", "vendor", "model", "state"}
+	hostLunPathsLabels []string = []string{"host_name", "host_id", "datacenter", "cluster_name", "lun", "state"}
+	hostHbaInfo                 = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "hba_info",
+			Help:      "Host storage adapter, value is always 1",
+		},
+		hostHbaInfoLabels,
+	)
+	hostHbaOnline = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "hba_online",
+			Help:      "Host storage adapter online (1 = online, 0 = offline)",
+		},
+		hostHbaLabels,
+	)
+	hostLunInfo = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "lun_info",
+			Help:      "Host SCSI LUN, value is always 1",
+		},
+		hostLunInfoLabels,
+	)
+	hostLunOk = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "lun_ok",
+			Help:      "Host SCSI LUN operational state ok (1 = ok, 0 = any other state)",
+		},
+		hostLunLabels,
+	)
+	hostLunPaths = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "host",
+			Name:      "lun_paths",
+			Help:      "Host SCSI LUN paths by state",
+		},
+		hostLunPathsLabels,
+	)
+
+	hostStorageMetrics = []*prometheus.GaugeVec{
+		hostHbaInfo, hostHbaOnline, hostLunInfo, hostLunOk, hostLunPaths,
+	}
+
+	// lunPathStates are the path states counted per LUN
+	lunPathStates = []string{
+		string(types.MultipathStateActive),
+		string(types.MultipathStateStandby),
+		string(types.MultipathStateDead),
+	}
+)
+
+func exportHostStorageMetrics(host mo.HostSystem, labels prometheus.Labels) {
+	if host.Config == nil || host.Config.StorageDevice == nil {
+		return
+	}
+	storage := host.Config.StorageDevice
+
+	for _, adapter := range storage.HostBusAdapter {
+		hba := adapter.GetHostHostBusAdapter()
+
+		hbaLabels := prometheus.Labels{
+			"hba": hba.Device,
+		}
+		for k, v := range labels {
+			hbaLabels[k] = v
+		}
+
+		infoLabels := prometheus.Labels{
+			"hba_type": hbaType(adapter),
+			"model":    hba.Model,
+			"driver":   hba.Driver,
+			"status":   hba.Status,
+		}
+		for k, v := range hbaLabels {
+			infoLabels[k] = v
+		}
+		hostHbaInfo.With(infoLabels).Set(1)
+
+		// Local adapters mostly report an unknown status
+		if hba.Status == "online" || hba.Status == "offline" {
+			hostHbaOnline.With(hbaLabels).Set(
+				boolValue(hba.Status == "online"),
+			)
+		}
+	}
+
+	// LUN canonical names by key, multipath info refers to LUNs by key
+	lunNames := make(map[string]string)
+	for _, scsiLun := range storage.ScsiLun {
+		lun := scsiLun.GetScsiLun()
+		lunNames[lun.Key] = lun.CanonicalName
+
+		lunLabels := prometheus.Labels{
+			"lun": lun.CanonicalName,
+		}
+		for k, v := range labels {
+			lunLabels[k] = v
+		}
+
+		state := "unknown"
+		if len(lun.OperationalState) > 0 {
+			state = lun.OperationalState[0]
+		}
+
+		infoLabels := prometheus.Labels{
+			"display_name": lun.DisplayName,
+			"lun_type":     lun.LunType,
+			"vendor":       lun.Vendor,
+			"model":        lun.Model,
+			"state":        state,
+		}
+		for k, v := range lunLabels {
+			infoLabels[k] = v
+		}
+		hostLunInfo.With(infoLabels).Set(1)
+
+		hostLunOk.With(lunLabels).Set(
+			boolValue(state == string(types.ScsiLunStateOk)),
+		)
+	}
+
+	if storage.MultipathInfo == nil {
+		return
+	}
+	for _, lun := range storage.MultipathInfo.Lun {
+		counts := make(map[string]int)
+		for _, path := range lun.Path {
+			counts[path.PathState]++
+		}
+
+		name := lunNames[lun.Lun]
+		if name == "" {
+			name = lun.Id
+		}
+
+		for _, state := range lunPathStates {
+			pathLabels := prometheus.Labels{
+				"lun":   name,
+				"state": state,
+			}
+			for k, v := range labels {
+				pathLabels[k] = v
+			}
+
+			hostLunPaths.With(pathLabels).Set(
+				float64(counts[state]),
+			)
+		}
+	}
+}
+
+func hbaType(adapter types.BaseHostHostBusAdapter) string {
+	switch adapter.(type) {
+	case *types.HostFibreChannelOverEthernetHba:
+		return "fcoe"
+	case *types.HostFibreChannelHba:
+		return "fc"
+	case *types.HostInternetScsiHba:
+		return "iscsi"
+	case *types.HostParallelScsiHba:
+		return "parallel_scsi"
+	case *types.HostBlockHba:
+		return "block"
+	case *types.HostSerialAttachedHba:
+		return "sas"
+	case *types.HostPcieHba:
+		return "pcie"
+	case *types.HostRdmaHba:
+		return "rdma"
+	case *types.HostTcpHba:
+		return "tcp"
+	}
+	return "unknown"
+}
@@ -0,0 +1,267 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	dvsLabels      []string = []string{"dvs_name"}
+	dvsInfoLabels  []string = []string{"dvs_name", "version"}
+	dvpgLabels     []string = []string{"portgroup_name", "dvs_name"}
+	dvpgInfoLabels []string = []string{"portgroup_name", "dvs_name", "vlan_type", "vlan"}
+	dvsInfo                 = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "dvs",
+			Name:      "info",
+			Help:      "Distributed virtual switch version, value is always 1",
+		},
+		dvsInfoLabels,
+	)
+	dvsMtu = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "dvs",
+			Name:      "mtu_bytes",
+			Help:      "Distributed virtual switch maximum MTU in bytes",
+		},
+		dvsLabels,
+	)
+	dvsHosts = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "dvs",
+			Name:      "hosts_total",
+			Help:      "Distributed virtual switch member hosts",
+		},
+		dvsLabels,
+	)
+	dvsPorts = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "dvs",
+			Name:      "ports_total",
+			Help:      "Distributed virtual switch ports",
+		},
+		dvsLabels,
+	)
+	dvpgInfo = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "dvpg",
+			Name:      "info",
+			Help:      "Distributed virtual portgroup VLAN configuration, value is always 1",
+		},
+		dvpgInfoLabels,
+	)
+	dvpgVlan = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "dvpg",
+			Name:      "vlan_id",
+			Help:      "Distributed virtual portgroup VLAN ID, 0 is untagged",
+		},
+		dvpgLabels,
+	)
+	dvpgPorts = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "dvpg",
+			Name:      "ports_total",
+			Help:      "Distributed virtual portgroup ports",
+		},
+		dvpgLabels,
+	)
+	dvpgPortsInUse = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "dvpg",
+			Name:      "ports_in_use",
+			Help:      "Distributed virtual portgroup ports connected to a VM or VMkernel NIC",
+		},
+		dvpgLabels,
+	)
+	dvpgVms = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "dvpg",
+			Name:      "vms_total",
+			Help:      "Distributed virtual portgroup connected VMs",
+		},
+		dvpgLabels,
+	)
+
+	networkMetrics = []*prometheus.GaugeVec{
+		dvsInfo, dvsMtu, dvsHosts, dvsPorts, dvpgInfo, dvpgVlan, dvpgPorts, dvpgPortsInUse, dvpgVms,
+	}
+)
+
+func ExportNetworkMetrics(ctx context.Context, client *govmomi.Client) error {
+
+	c := client.Client
+
+	// Create a view manager
+	m := view.NewManager(c)
+
+	kinds := []string{"DistributedVirtualSwitch", "VmwareDistributedVirtualSwitch", "DistributedVirtualPortgroup"}
+	containerView, err := m.CreateContainerView(ctx, c.ServiceContent.RootFolder, kinds, true)
+	if err != nil {
+		log.Printf("Error creating container view: %v", err)
+		return err
+	}
+	defer containerView.Destroy(ctx)
+
+	var switches []mo.DistributedVirtualSwitch
+	err = containerView.Retrieve(ctx, kinds[:2], []string{"name", "summary", "config"}, &switches)
+	if err != nil {
+		log.Printf("Error retrieving distributed virtual switches: %v", err)
+		return err
+	}
+
+	var portgroups []mo.DistributedVirtualPortgroup
+	err = containerView.Retrieve(ctx, kinds[2:], []string{"name", "config", "vm"}, &portgroups)
+	if err != nil {
+		log.Printf("Error retrieving distributed virtual portgroups: %v", err)
+		return err
+	}
+
+	// The ports in use of every portgroup, by portgroup key
+	connected := true
+	inUse := make(map[string]int)
+	switchNames := make(map[types.ManagedObjectReference]string)
+	uplinks := make(map[types.ManagedObjectReference]bool)
+	for _, dvs := range switches {
+		switchNames[dvs.Self] = dvs.Name
+		if dvs.Config != nil {
+			for _, pg := range dvs.Config.GetDVSConfigInfo().UplinkPortgroup {
+				uplinks[pg] = true
+			}
+		}
+
+		ports, err := object.NewDistributedVirtualSwitch(c, dvs.Self).FetchDVPorts(ctx, &types.DistributedVirtualSwitchPortCriteria{
+			Connected: &connected,
+		})
+		if err != nil {
+			log.Printf("Error retrieving ports of %s: %v", dvs.Name, err)
+			return err
+		}
+		for _, port := range ports {
+			if port.Connectee != nil {
+				inUse[port.PortgroupKey]++
+			}
+		}
+	}
+
+	// Removed switches and portgroups must disappear
+	for _, gauge := range networkMetrics {
+		gauge.Reset()
+	}
+
+	for _, dvs := range switches {
+		labels := prometheus.Labels{
+			"dvs_name": dvs.Name,
+		}
+
+		version := ""
+		if dvs.Summary.ProductInfo != nil {
+			version = dvs.Summary.ProductInfo.Version
+		}
+		dvsInfo.With(prometheus.Labels{
+			"dvs_name": dvs.Name,
+			"version":  version,
+		}).Set(1)
+
+		if config, ok := dvs.Config.(*types.VMwareDVSConfigInfo); ok {
+			dvsMtu.With(labels).Set(
+				float64(config.MaxMtu),
+			)
+		}
+		dvsHosts.With(labels).Set(
+			float64(len(dvs.Summary.HostMember)),
+		)
+		dvsPorts.With(labels).Set(
+			float64(dvs.Summary.NumPorts),
+		)
+	}
+
+	for _, pg := range portgroups {
+		// Uplink portgroups carry no VM traffic
+		if uplinks[pg.Self] || (pg.Config.Uplink != nil && *pg.Config.Uplink) {
+			continue
+		}
+
+		dvsName := ""
+		if pg.Config.DistributedVirtualSwitch != nil {
+			dvsName = switchNames[*pg.Config.DistributedVirtualSwitch]
+		}
+
+		labels := prometheus.Labels{
+			"portgroup_name": pg.Name,
+			"dvs_name":       dvsName,
+		}
+
+		vlanType, vlan := dvpgVlanConfig(pg.Config.DefaultPortConfig)
+		dvpgInfo.With(prometheus.Labels{
+			"portgroup_name": pg.Name,
+			"dvs_name":       dvsName,
+			"vlan_type":      vlanType,
+			"vlan":           vlan,
+		}).Set(1)
+
+		if spec, ok := dvpgVlanSpec(pg.Config.DefaultPortConfig).(*types.VmwareDistributedVirtualSwitchVlanIdSpec); ok {
+			dvpgVlan.With(labels).Set(
+				float64(spec.VlanId),
+			)
+		}
+		dvpgPorts.With(labels).Set(
+			float64(pg.Config.NumPorts),
+		)
+		dvpgPortsInUse.With(labels).Set(
+			float64(inUse[pg.Config.Key]),
+		)
+		dvpgVms.With(labels).Set(
+			float64(len(pg.Vm)),
+		)
+	}
+	return nil
+}
+
+func dvpgVlanSpec(setting types.BaseDVPortSetting) types.BaseVmwareDistributedVirtualSwitchVlanSpec {
+	if s, ok := setting.(*types.VMwareDVSPortSetting); ok {
+		return s.Vlan
+	}
+	return nil
+}
+
+// dvpgVlanConfig returns the VLAN type (vlan, trunk, pvlan) of a portgroup
+// and its VLAN ID, trunked VLAN ranges or private VLAN ID
+func dvpgVlanConfig(setting types.BaseDVPortSetting) (string, string) {
+	switch spec := dvpgVlanSpec(setting).(type) {
+	case *types.VmwareDistributedVirtualSwitchVlanIdSpec:
+		return "vlan", fmt.Sprintf("%d", spec.VlanId)
+	case *types.VmwareDistributedVirtualSwitchTrunkVlanSpec:
+		var ranges []string
+		for _, r := range spec.VlanId {
+			if r.Start == r.End {
+				ranges = append(ranges, fmt.Sprintf("%d", r.Start))
+			} else {
+				ranges = append(ranges, fmt.Sprintf("%d-%d", r.Start, r.End))
+			}
+		}
+		return "trunk", strings.Join(ranges, ",")
+	case *types.VmwareDistributedVirtualSwitchPvlanSpec:
+		return "pvlan", fmt.Sprintf("%d", spec.PvlanId)
+	}
+	return "", ""
+}
@@ -0,0 +1,185 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	vmNicLabels     []string = []string{"machine_name", "host_name", "datacenter", "cluster_name", "nic_key", "nic_label", "mac_address", "network_name"}
+	vmNicInfoLabels []string = append(vmNicLabels, "adapter_type")
+	vmNicIpLabels   []string = append(vmNicLabels, "ip_address")
+	vmNicConnected           = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "nic_connected",
+			Help:      "VM network adapter is connected (1 = true, 0 = false)",
+		},
+		vmNicLabels,
+	)
+	vmNicGuestConnected = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "nic_guest_connected",
+			Help:      "VM network adapter is connected according to the guest (1 = true, 0 = false)",
+		},
+		vmNicLabels,
+	)
+	vmNicGuestIp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "nic_guest_ip",
+			Help:      "VM network adapter IP address reported by the guest, value is always 1",
+		},
+		vmNicIpLabels,
+	)
+	vmNicInfo = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "nic_info",
+			Help:      "VM network adapter information, value is always 1",
+		},
+		vmNicInfoLabels,
+	)
+	vmNicStartConnected = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "nic_start_connected",
+			Help:      "VM network adapter connects at power on (1 = true, 0 = false)",
+		},
+		vmNicLabels,
+	)
+)
+
+// populateNetworkMappings resolves the names of all standard, distributed
+// and opaque networks by their managed object ID, distributed portgroups
+// also by their portgroup key, which is what NIC backings refer to
+func populateNetworkMappings(ctx context.Context, c *vim25.Client) error {
+	m := view.NewManager(c)
+
+	v, err := m.CreateContainerView(ctx, c.ServiceContent.RootFolder, []string{"Network"}, true)
+	if err != nil {
+		return err
+	}
+	defer v.Destroy(ctx)
+
+	var networks []mo.Network
+	if err = v.Retrieve(ctx, []string{"Network"}, []string{"name"}, &networks); err != nil {
+		return err
+	}
+
+	for _, network := range networks {
+		populateNetworkMapping(network.Self.Value, network.Name)
+	}
+
+	var portgroups []mo.DistributedVirtualPortgroup
+	if err = v.Retrieve(ctx, []string{"DistributedVirtualPortgroup"}, []string{"name", "config.key"}, &portgroups); err != nil {
+		return err
+	}
+
+	for _, portgroup := range portgroups {
+		populateNetworkMapping(portgroup.Config.Key, portgroup.Name)
+	}
+	return nil
+}
+
+func exportVirtualMachineNetworkMetrics(vm mo.VirtualMachine, labels prometheus.Labels) {
+	if vm.Config == nil {
+		return
+	}
+
+	devices := object.VirtualDeviceList(vm.Config.Hardware.Device)
+	for _, device := range devices.SelectByType((*types.VirtualEthernetCard)(nil)) {
+		nic := device.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+
+		nicLabel := ""
+		if description := nic.DeviceInfo.GetDescription(); description != nil {
+			nicLabel = description.Label
+		}
+
+		nicLabels := prometheus.Labels{
+			"machine_name": labels["machine_name"],
+			"host_name":    labels["host_name"],
+			"datacenter":   labels["datacenter"],
+			"cluster_name": labels["cluster_name"],
+			"nic_key":      fmt.Sprintf("%d", nic.Key),
+			"nic_label":    nicLabel,
+			"mac_address":  nic.MacAddress,
+			"network_name": nicNetworkName(nic.Backing),
+		}
+
+		infoLabels := prometheus.Labels{
+			"adapter_type": strings.ToLower(strings.TrimPrefix(devices.TypeName(device), "Virtual")),
+		}
+		for k, v := range nicLabels {
+			infoLabels[k] = v
+		}
+		vmNicInfo.With(infoLabels).Set(1)
+
+		connected, startConnected := 0.0, 0.0
+		if nic.Connectable != nil {
+			if nic.Connectable.Connected {
+				connected = 1
+			}
+			if nic.Connectable.StartConnected {
+				startConnected = 1
+			}
+		}
+		vmNicConnected.With(nicLabels).Set(connected)
+		vmNicStartConnected.With(nicLabels).Set(startConnected)
+
+		if vm.Guest == nil {
+			continue
+		}
+		for _, guestNic := range vm.Guest.Net {
+			if guestNic.DeviceConfigId != nic.Key {
+				continue
+			}
+			guestConnected := 0.0
+			if guestNic.Connected {
+				guestConnected = 1
+			}
+			vmNicGuestConnected.With(nicLabels).Set(guestConnected)
+			for _, ip := range guestNic.IpAddress {
+				ipLabels := prometheus.Labels{
+					"ip_address": ip,
+				}
+				for k, v := range nicLabels {
+					ipLabels[k] = v
+				}
+				vmNicGuestIp.With(ipLabels).Set(1)
+			}
+		}
+	}
+}
+
+// nicNetworkName returns the portgroup, distributed portgroup or opaque
+// network name a network adapter is backed by
+func nicNetworkName(backing types.BaseVirtualDeviceBackingInfo) string {
+	switch b := backing.(type) {
+	case *types.VirtualEthernetCardNetworkBackingInfo:
+		return b.DeviceName
+	case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+		if name := NetworkMapping[b.Port.PortgroupKey]; name != "" {
+			return name
+		}
+		return b.Port.PortgroupKey
+	case *types.VirtualEthernetCardOpaqueNetworkBackingInfo:
+		return b.OpaqueNetworkId
+	}
+	return "unknown"
+}
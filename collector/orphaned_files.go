@@ -0,0 +1,247 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// OrphanedFilesInterval is how often the datastores are searched for disks
+// no registered VM uses, 0 disables the search. The search can take minutes
+// and runs apart from the other collectors
+var OrphanedFilesInterval time.Duration
+
+var (
+	orphanedLabels []string = []string{"datastore_name"}
+	orphanedFiles           = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "orphaned_files_total",
+			Help:      "Datastore .vmdk files no registered VM uses",
+		},
+		orphanedLabels,
+	)
+	orphanedBytes = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "ds",
+			Name:      "orphaned_bytes",
+			Help:      "Datastore .vmdk files no registered VM uses in bytes",
+		},
+		orphanedLabels,
+	)
+
+	// orphanedScan is the result of the last search, served as JSON
+	orphanedScan   orphanedFileScan
+	orphanedScanMu sync.Mutex
+)
+
+type orphanedFileScan struct {
+	ScannedAt *time.Time     `json:"scanned_at"`
+	Files     []orphanedFile `json:"files"`
+}
+
+type orphanedFile struct {
+	Datastore string     `json:"datastore"`
+	Path      string     `json:"path"`
+	SizeBytes int64      `json:"size_bytes"`
+	Modified  *time.Time `json:"modified,omitempty"`
+}
+
+func ExportOrphanedFileMetrics(ctx context.Context, client *govmomi.Client) error {
+	if OrphanedFilesInterval == 0 {
+		return nil
+	}
+
+	c := client.Client
+
+	// Create a view manager
+	m := view.NewManager(c)
+
+	containerView, err := m.CreateContainerView(ctx, c.ServiceContent.RootFolder, []string{"Datastore", "VirtualMachine"}, true)
+	if err != nil {
+		log.Printf("Error creating container view: %v", err)
+		return err
+	}
+	defer containerView.Destroy(ctx)
+
+	// Every file of a registered VM, by datastore path
+	var vms []mo.VirtualMachine
+	if err = containerView.Retrieve(ctx, []string{"VirtualMachine"}, []string{"layoutEx.file"}, &vms); err != nil {
+		log.Printf("Error retrieving VM files: %v", err)
+		return err
+	}
+	used := make(map[string]bool)
+	for _, vm := range vms {
+		if vm.LayoutEx == nil {
+			continue
+		}
+		for _, file := range vm.LayoutEx.File {
+			used[normalizeDatastorePath(file.Name)] = true
+		}
+	}
+
+	var dss []mo.Datastore
+	if err = containerView.Retrieve(ctx, []string{"Datastore"}, []string{"name", "browser", "summary.accessible", "summary.type"}, &dss); err != nil {
+		log.Printf("Error retrieving datastores: %v", err)
+		return err
+	}
+
+	scannedAt := time.Now()
+	scan := orphanedFileScan{ScannedAt: &scannedAt, Files: []orphanedFile{}}
+
+	// Removed datastores must disappear
+	orphanedFiles.Reset()
+	orphanedBytes.Reset()
+
+	// A datastore failing the search must not keep the others from being
+	// searched
+	var searchErr error
+	for _, ds := range dss {
+		if !ds.Summary.Accessible {
+			continue
+		}
+		// VM layouts list the object namespace UUID folders of vSAN and vVol
+		// datastores while the browser lists their friendly names, every
+		// disk would look orphaned
+		switch types.HostFileSystemVolumeFileSystemType(ds.Summary.Type) {
+		case types.HostFileSystemVolumeFileSystemTypeVsan, types.HostFileSystemVolumeFileSystemTypeVVOL:
+			continue
+		}
+
+		files, err := searchVmdkFiles(ctx, object.NewHostDatastoreBrowser(c, ds.Browser), ds.Name)
+		if err != nil {
+			log.Printf("Error searching datastore %s: %v", ds.Name, err)
+			searchErr = err
+			continue
+		}
+
+		count, size := 0, int64(0)
+		for _, file := range files {
+			if used[file.Path] {
+				continue
+			}
+			count++
+			size += file.SizeBytes
+			scan.Files = append(scan.Files, file)
+		}
+
+		labels := prometheus.Labels{
+			"datastore_name": ds.Name,
+		}
+		orphanedFiles.With(labels).Set(float64(count))
+		orphanedBytes.With(labels).Set(float64(size))
+	}
+
+	sort.Slice(scan.Files, func(i, j int) bool {
+		return scan.Files[i].Path < scan.Files[j].Path
+	})
+
+	orphanedScanMu.Lock()
+	orphanedScan = scan
+	orphanedScanMu.Unlock()
+	return searchErr
+}
+
+// searchVmdkFiles lists the .vmdk files of a datastore, without the change
+// tracking files which VM layouts do not list
+func searchVmdkFiles(ctx context.Context, browser *object.HostDatastoreBrowser, datastore string) ([]orphanedFile, error) {
+	spec := types.HostDatastoreBrowserSearchSpec{
+		MatchPattern: []string{"*.vmdk"},
+		Details: &types.FileQueryFlags{
+			FileSize:     true,
+			Modification: true,
+		},
+	}
+
+	task, err := browser.SearchDatastoreSubFolders(ctx, fmt.Sprintf("[%s]", datastore), &spec)
+	if err != nil {
+		return nil, err
+	}
+	info, err := task.WaitForResult(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results, ok := info.Result.(types.ArrayOfHostDatastoreBrowserSearchResults)
+	if !ok {
+		return nil, nil
+	}
+
+	var files []orphanedFile
+	for _, result := range results.HostDatastoreBrowserSearchResults {
+		for _, f := range result.File {
+			file := f.GetFileInfo()
+			if strings.HasSuffix(file.Path, "-ctk.vmdk") {
+				continue
+			}
+
+			p := normalizeDatastorePath(result.FolderPath + "/" + file.Path)
+			if vmlessDiskFolder(p) {
+				continue
+			}
+
+			files = append(files, orphanedFile{
+				Datastore: datastore,
+				Path:      p,
+				SizeBytes: file.FileSize,
+				Modified:  file.Modification,
+			})
+		}
+	}
+	return files, nil
+}
+
+// normalizeDatastorePath formats a datastore path as "[ds] folder/file", so
+// search results and VM layouts compare equal whatever their separators
+func normalizeDatastorePath(s string) string {
+	var p object.DatastorePath
+	if !p.FromString(s) {
+		return s
+	}
+	p.Path = strings.TrimPrefix(path.Clean("/"+p.Path), "/")
+	return p.String()
+}
+
+// vmlessDiskFolder reports whether a disk lives in a folder of disks which
+// belong to no VM by design: first class disks (including Kubernetes CNS
+// volumes) in fcd/ and content library items in contentlib-*/
+func vmlessDiskFolder(s string) bool {
+	var p object.DatastorePath
+	if !p.FromString(s) {
+		return false
+	}
+	folder, _, _ := strings.Cut(p.Path, "/")
+	return folder == "fcd" || strings.HasPrefix(folder, "contentlib-")
+}
+
+// OrphanedFilesHandler serves the orphaned files found by the last search
+// as JSON
+func OrphanedFilesHandler(w http.ResponseWriter, r *http.Request) {
+	orphanedScanMu.Lock()
+	scan := orphanedScan
+	orphanedScanMu.Unlock()
+
+	if scan.Files == nil {
+		scan.Files = []orphanedFile{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(scan)
+}
@@ -0,0 +1,130 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vmware/govmomi/performance"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	// perfBatchSize is the number of entities queried per QueryPerf call
+	perfBatchSize = 100
+	// perfRealtimeInterval is the realtime sampling interval in seconds
+	perfRealtimeInterval = 20
+)
+
+// perfMetric maps a PerformanceManager counter (group.name.rollup) to a gauge,
+// the sample value is multiplied by scale
+type perfMetric struct {
+	counter string
+	scale   float64
+	gauge   *prometheus.GaugeVec
+}
+
+// perfInstanceLabels labels a sample with its counter instance
+func perfInstanceLabels(instance string) prometheus.Labels {
+	return prometheus.Labels{
+		"counter_instance": instance,
+	}
+}
+
+// perfSample is a gauge value collected before the gauges are swapped
+type perfSample struct {
+	gauge  *prometheus.GaugeVec
+	labels prometheus.Labels
+	value  float64
+}
+
+// exportPerfMetrics queries the latest realtime sample of the given metrics
+// for all entities and sets the gauges with the entity labels plus the
+// labels instanceLabels derives from the counter instance, samples for which
+// it returns nil are skipped. The gauges are only replaced once every batch
+// has been queried, a failed query keeps the previous samples
+func exportPerfMetrics(ctx context.Context, c *vim25.Client, entities map[types.ManagedObjectReference]prometheus.Labels, metrics []perfMetric, instanceLabels func(string) prometheus.Labels) error {
+	var samples []perfSample
+	if len(entities) == 0 || len(metrics) == 0 {
+		swapPerfSamples(metrics, samples)
+		return nil
+	}
+
+	var counters []string
+	byCounter := make(map[string][]perfMetric)
+	for _, metric := range metrics {
+		if _, ok := byCounter[metric.counter]; !ok {
+			counters = append(counters, metric.counter)
+		}
+		byCounter[metric.counter] = append(byCounter[metric.counter], metric)
+	}
+
+	var refs []types.ManagedObjectReference
+	for ref := range entities {
+		refs = append(refs, ref)
+	}
+
+	pm := performance.NewManager(c)
+	spec := types.PerfQuerySpec{
+		MaxSample:  1,
+		IntervalId: perfRealtimeInterval,
+	}
+
+	for start := 0; start < len(refs); start += perfBatchSize {
+		batch := refs[start:min(start+perfBatchSize, len(refs))]
+
+		sample, err := pm.SampleByName(ctx, spec, counters, batch)
+		if err != nil {
+			return err
+		}
+
+		series, err := pm.ToMetricSeries(ctx, sample)
+		if err != nil {
+			return err
+		}
+
+		for _, entity := range series {
+			entityLabels := entities[entity.Entity]
+			for _, value := range entity.Value {
+				if len(value.Value) == 0 {
+					continue
+				}
+				// -1 means the counter has no data for this sample
+				latest := value.Value[len(value.Value)-1]
+				if latest < 0 {
+					continue
+				}
+
+				labels := instanceLabels(value.Instance)
+				if labels == nil {
+					continue
+				}
+				for k, v := range entityLabels {
+					labels[k] = v
+				}
+
+				for _, metric := range byCounter[value.Name] {
+					samples = append(samples, perfSample{
+						gauge:  metric.gauge,
+						labels: labels,
+						value:  float64(latest) * metric.scale,
+					})
+				}
+			}
+		}
+	}
+
+	swapPerfSamples(metrics, samples)
+	return nil
+}
+
+// swapPerfSamples replaces the series of the given metrics with the samples,
+// dropping those of removed entities
+func swapPerfSamples(metrics []perfMetric, samples []perfSample) {
+	for _, metric := range metrics {
+		metric.gauge.Reset()
+	}
+	for _, sample := range samples {
+		sample.gauge.With(sample.labels).Set(sample.value)
+	}
+}
@@ -0,0 +1,251 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/performance"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// PerfCounters holds the configured counters per entity type (vm, host,
+// datastore). Counters are named group.name.rollup, the rollup may be left
+// out. Entity types without an entry keep their built-in counters, an empty
+// list disables the collection for that entity type.
+var PerfCounters = make(map[string][]string)
+
+// perfEntityType describes the metrics of an entity type the performance
+// collectors support
+type perfEntityType struct {
+	subsystem string
+	labels    []string
+	builtin   []perfMetric
+	// group restricts the counters to a counter group, datastore metrics are
+	// host counters instanced by datastore
+	group string
+}
+
+var (
+	perfEntityTypes = map[string]perfEntityType{
+		"vm": {
+			subsystem: "vm",
+			labels:    vmPerfLabels,
+			builtin:   vmPerfMetrics,
+		},
+		"host": {
+			subsystem: "host",
+			labels:    hostPerfLabels,
+			builtin:   hostPerfMetrics,
+		},
+		"datastore": {
+			subsystem: "ds",
+			labels:    datastorePerfLabels,
+			builtin:   datastorePerfMetrics,
+			group:     "datastore",
+		},
+	}
+
+	// perfRollupPreference is the order a rollup is picked in when a counter
+	// is configured without one
+	perfRollupPreference = []types.PerfSummaryType{
+		types.PerfSummaryTypeAverage,
+		types.PerfSummaryTypeSummation,
+		types.PerfSummaryTypeLatest,
+		types.PerfSummaryTypeMaximum,
+		types.PerfSummaryTypeMinimum,
+		types.PerfSummaryTypeNone,
+	}
+
+	// perfSelection holds the resolved metrics of the configured entity types
+	perfSelection = make(map[string][]perfMetric)
+
+	metricNameRegexp = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+)
+
+// ResolvePerfCounters resolves the configured counters through the
+// PerformanceManager counter info and registers a gauge for every counter
+// without a built-in metric. All unknown or unsupported counters are reported
+// at once.
+func ResolvePerfCounters(ctx context.Context, client *govmomi.Client) error {
+	if len(PerfCounters) == 0 {
+		return nil
+	}
+
+	pm := performance.NewManager(client.Client)
+	info, err := pm.CounterInfoByName(ctx)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, entityType := range sortedKeys(PerfCounters) {
+		entity, ok := perfEntityTypes[entityType]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown entity type %q", entityType))
+			continue
+		}
+
+		builtin := make(map[string]perfMetric)
+		for _, metric := range entity.builtin {
+			builtin[metric.counter] = metric
+		}
+
+		metrics := []perfMetric{}
+		for _, name := range PerfCounters[entityType] {
+			counter, err := lookupPerfCounter(info, name)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", entityType, err))
+				continue
+			}
+			if entity.group != "" && counter.GroupInfo.GetElementDescription().Key != entity.group {
+				problems = append(problems, fmt.Sprintf("%s: counter %q is not in the %q group", entityType, name, entity.group))
+				continue
+			}
+
+			if metric, ok := builtin[counter.Name()]; ok {
+				metrics = append(metrics, metric)
+				continue
+			}
+
+			metric, err := newPerfMetric(entity, counter)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", entityType, err))
+				continue
+			}
+			builtin[counter.Name()] = metric
+			metrics = append(metrics, metric)
+		}
+		perfSelection[entityType] = metrics
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid performance counters:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// selectedPerfMetrics returns the configured metrics of an entity type, or
+// its built-in ones when it is not configured
+func selectedPerfMetrics(entityType string) []perfMetric {
+	if metrics, ok := perfSelection[entityType]; ok {
+		return metrics
+	}
+	return perfEntityTypes[entityType].builtin
+}
+
+// lookupPerfCounter finds a counter by group.name.rollup, or by group.name
+// picking the rollup by perfRollupPreference
+func lookupPerfCounter(info map[string]*types.PerfCounterInfo, name string) (*types.PerfCounterInfo, error) {
+	parts := strings.Split(name, ".")
+	switch len(parts) {
+	case 3:
+		if counter, ok := info[name]; ok {
+			return counter, nil
+		}
+	case 2:
+		for _, rollup := range perfRollupPreference {
+			if counter, ok := info[name+"."+string(rollup)]; ok {
+				return counter, nil
+			}
+		}
+	default:
+		return nil, fmt.Errorf("counter %q is not named group.name[.rollup]", name)
+	}
+
+	// Point at the rollups which do exist for the counter
+	prefix := strings.Join(parts[:2], ".") + "."
+	var candidates []string
+	for key := range info {
+		if strings.HasPrefix(key, prefix) {
+			candidates = append(candidates, key)
+		}
+	}
+	if len(candidates) > 0 {
+		sort.Strings(candidates)
+		return nil, fmt.Errorf("counter %q not found, available: %s", name, strings.Join(candidates, ", "))
+	}
+	return nil, fmt.Errorf("counter %q not found", name)
+}
+
+// newPerfMetric registers a gauge named after the counter, with the sample
+// converted to the unit the rest of the exporter uses
+func newPerfMetric(entity perfEntityType, counter *types.PerfCounterInfo) (perfMetric, error) {
+	suffix, scale := perfUnit(counter.UnitInfo.GetElementDescription().Key)
+
+	name := metricNameRegexp.ReplaceAllString(strings.ReplaceAll(counter.Name(), ".", "_"), "${1}_${2}")
+	name = strings.ToLower(name) + suffix
+
+	help := counter.NameInfo.GetElementDescription().Summary
+	if help == "" {
+		help = counter.NameInfo.GetElementDescription().Label
+	}
+
+	gauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: entity.subsystem,
+			Name:      name,
+			Help:      fmt.Sprintf("%s (%s)", help, counter.Name()),
+		},
+		entity.labels,
+	)
+	if err := prometheus.Register(gauge); err != nil {
+		return perfMetric{}, fmt.Errorf("counter %q: %v", counter.Name(), err)
+	}
+
+	return perfMetric{
+		counter: counter.Name(),
+		scale:   scale,
+		gauge:   gauge,
+	}, nil
+}
+
+// perfUnit returns the metric name suffix and the scale converting a
+// PerformanceManager unit to bytes, milliseconds, percent or MHz
+func perfUnit(unit string) (string, float64) {
+	switch types.PerformanceManagerUnit(unit) {
+	case types.PerformanceManagerUnitPercent:
+		return "_percent", 0.01
+	case types.PerformanceManagerUnitKiloBytes:
+		return "_bytes", 1024
+	case types.PerformanceManagerUnitMegaBytes:
+		return "_bytes", 1024 * 1024
+	case types.PerformanceManagerUnitTeraBytes:
+		return "_bytes", 1024 * 1024 * 1024 * 1024
+	case types.PerformanceManagerUnitKiloBytesPerSecond:
+		return "_bytes_per_second", 1024
+	case types.PerformanceManagerUnitMegaBytesPerSecond:
+		return "_bytes_per_second", 1024 * 1024
+	case types.PerformanceManagerUnitMegaHertz:
+		return "_mhz", 1
+	case types.PerformanceManagerUnitNanosecond:
+		return "_milliseconds", 0.000001
+	case types.PerformanceManagerUnitMicrosecond:
+		return "_milliseconds", 0.001
+	case types.PerformanceManagerUnitMillisecond:
+		return "_milliseconds", 1
+	case types.PerformanceManagerUnitSecond:
+		return "_seconds", 1
+	case types.PerformanceManagerUnitWatt:
+		return "_watts", 1
+	case types.PerformanceManagerUnitJoule:
+		return "_joules", 1
+	case types.PerformanceManagerUnitCelsius:
+		return "_celsius", 1
+	}
+	return "", 1
+}
+
+func sortedKeys(m map[string][]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
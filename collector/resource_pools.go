@@ -0,0 +1,279 @@
+package collector
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	resourcePoolLabels         []string = []string{"pool_name", "pool_path", "pool_type", "cluster_name"}
+	resourcePoolCpuReservation          = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "resource_pool",
+			Name:      "cpu_reservation_mhz",
+			Help:      "Resource pool CPU reservation in MHz",
+		},
+		resourcePoolLabels,
+	)
+	resourcePoolCpuLimit = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "resource_pool",
+			Name:      "cpu_limit_mhz",
+			Help:      "Resource pool CPU limit in MHz, -1 is unlimited",
+		},
+		resourcePoolLabels,
+	)
+	resourcePoolCpuShares = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "resource_pool",
+			Name:      "cpu_shares",
+			Help:      "Resource pool CPU shares",
+		},
+		resourcePoolLabels,
+	)
+	resourcePoolCpuExpandable = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "resource_pool",
+			Name:      "cpu_expandable_reservation",
+			Help:      "Resource pool CPU reservation expandable (1 = expandable, 0 = fixed)",
+		},
+		resourcePoolLabels,
+	)
+	resourcePoolCpuReservationUsed = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "resource_pool",
+			Name:      "cpu_reservation_used_mhz",
+			Help:      "Resource pool CPU reservation used by the pool and its children in MHz",
+		},
+		resourcePoolLabels,
+	)
+	resourcePoolCpuUsage = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "resource_pool",
+			Name:      "cpu_usage_mhz",
+			Help:      "Resource pool CPU usage in MHz",
+		},
+		resourcePoolLabels,
+	)
+	resourcePoolMemoryReservation = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "resource_pool",
+			Name:      "memory_reservation_bytes",
+			Help:      "Resource pool memory reservation in bytes",
+		},
+		resourcePoolLabels,
+	)
+	resourcePoolMemoryLimit = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "resource_pool",
+			Name:      "memory_limit_bytes",
+			Help:      "Resource pool memory limit in bytes, -1 is unlimited",
+		},
+		resourcePoolLabels,
+	)
+	resourcePoolMemoryShares = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "resource_pool",
+			Name:      "memory_shares",
+			Help:      "Resource pool memory shares",
+		},
+		resourcePoolLabels,
+	)
+	resourcePoolMemoryExpandable = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "resource_pool",
+			Name:      "memory_expandable_reservation",
+			Help:      "Resource pool memory reservation expandable (1 = expandable, 0 = fixed)",
+		},
+		resourcePoolLabels,
+	)
+	resourcePoolMemoryReservationUsed = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "resource_pool",
+			Name:      "memory_reservation_used_bytes",
+			Help:      "Resource pool memory reservation used by the pool and its children in bytes",
+		},
+		resourcePoolLabels,
+	)
+	resourcePoolMemoryUsage = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "resource_pool",
+			Name:      "memory_usage_bytes",
+			Help:      "Resource pool memory usage in bytes",
+		},
+		resourcePoolLabels,
+	)
+	resourcePoolVms = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "resource_pool",
+			Name:      "vms_total",
+			Help:      "Resource pool VMs, without those of child pools",
+		},
+		resourcePoolLabels,
+	)
+
+	resourcePoolMetrics = []*prometheus.GaugeVec{
+		resourcePoolCpuReservation, resourcePoolCpuLimit, resourcePoolCpuShares, resourcePoolCpuExpandable,
+		resourcePoolCpuReservationUsed, resourcePoolCpuUsage, resourcePoolMemoryReservation, resourcePoolMemoryLimit,
+		resourcePoolMemoryShares, resourcePoolMemoryExpandable, resourcePoolMemoryReservationUsed, resourcePoolMemoryUsage,
+		resourcePoolVms,
+	}
+)
+
+func ExportResourcePoolMetrics(ctx context.Context, client *govmomi.Client) error {
+
+	c := client.Client
+
+	// Create a view manager
+	m := view.NewManager(c)
+
+	// Create a container view for resource pools, vApps are resource pools
+	kinds := []string{"ResourcePool", "VirtualApp"}
+	containerView, err := m.CreateContainerView(ctx, c.ServiceContent.RootFolder, kinds, true)
+	if err != nil {
+		log.Printf("Error creating container view: %v", err)
+		return err
+	}
+	defer containerView.Destroy(ctx)
+
+	var pools []mo.ResourcePool
+	err = containerView.Retrieve(ctx, kinds, []string{"name", "parent", "owner", "config", "runtime", "vm"}, &pools)
+	if err != nil {
+		log.Printf("Error retrieving resource pools: %v", err)
+		return err
+	}
+
+	byRef := make(map[types.ManagedObjectReference]mo.ResourcePool)
+	var ownerRefs []types.ManagedObjectReference
+	seen := make(map[types.ManagedObjectReference]bool)
+	for _, pool := range pools {
+		byRef[pool.Self] = pool
+		if !seen[pool.Owner] {
+			seen[pool.Owner] = true
+			ownerRefs = append(ownerRefs, pool.Owner)
+		}
+	}
+
+	// The clusters or standalone hosts owning the pools
+	owners := make(map[types.ManagedObjectReference]string)
+	if len(ownerRefs) > 0 {
+		var computeResources []mo.ManagedEntity
+		pc := property.DefaultCollector(c)
+		if err = pc.Retrieve(ctx, ownerRefs, []string{"name"}, &computeResources); err != nil {
+			log.Printf("Error retrieving resource pool owners: %v", err)
+			return err
+		}
+		for _, cr := range computeResources {
+			owners[cr.Self] = cr.Name
+		}
+	}
+
+	// Removed and renamed pools must disappear
+	for _, gauge := range resourcePoolMetrics {
+		gauge.Reset()
+	}
+
+	for _, pool := range pools {
+		labels := prometheus.Labels{
+			"pool_name":    pool.Name,
+			"pool_path":    resourcePoolPath(pool, byRef),
+			"pool_type":    resourcePoolType(pool.Self.Type),
+			"cluster_name": owners[pool.Owner],
+		}
+
+		cpu := pool.Config.CpuAllocation
+		memory := pool.Config.MemoryAllocation
+
+		if cpu.Reservation != nil {
+			resourcePoolCpuReservation.With(labels).Set(float64(*cpu.Reservation))
+		}
+		if cpu.Limit != nil {
+			resourcePoolCpuLimit.With(labels).Set(float64(*cpu.Limit))
+		}
+		if cpu.Shares != nil {
+			resourcePoolCpuShares.With(labels).Set(float64(cpu.Shares.Shares))
+		}
+		resourcePoolCpuExpandable.With(labels).Set(
+			boolValue(cpu.ExpandableReservation != nil && *cpu.ExpandableReservation),
+		)
+		resourcePoolCpuReservationUsed.With(labels).Set(
+			float64(pool.Runtime.Cpu.ReservationUsed),
+		)
+		resourcePoolCpuUsage.With(labels).Set(
+			float64(pool.Runtime.Cpu.OverallUsage),
+		)
+
+		// The memory allocation is in MB
+		if memory.Reservation != nil {
+			resourcePoolMemoryReservation.With(labels).Set(float64(*memory.Reservation * 1024 * 1024))
+		}
+		if memory.Limit != nil {
+			limit := *memory.Limit
+			if limit > 0 {
+				limit *= 1024 * 1024
+			}
+			resourcePoolMemoryLimit.With(labels).Set(float64(limit))
+		}
+		if memory.Shares != nil {
+			resourcePoolMemoryShares.With(labels).Set(float64(memory.Shares.Shares))
+		}
+		resourcePoolMemoryExpandable.With(labels).Set(
+			boolValue(memory.ExpandableReservation != nil && *memory.ExpandableReservation),
+		)
+		resourcePoolMemoryReservationUsed.With(labels).Set(
+			float64(pool.Runtime.Memory.ReservationUsed),
+		)
+		resourcePoolMemoryUsage.With(labels).Set(
+			float64(pool.Runtime.Memory.OverallUsage),
+		)
+
+		resourcePoolVms.With(labels).Set(
+			float64(len(pool.Vm)),
+		)
+	}
+	return nil
+}
+
+// resourcePoolPath returns the names of the pools from the root pool of the
+// cluster down to the pool, joined by "/"
+func resourcePoolPath(pool mo.ResourcePool, pools map[types.ManagedObjectReference]mo.ResourcePool) string {
+	names := []string{pool.Name}
+	for pool.Parent != nil {
+		parent, ok := pools[*pool.Parent]
+		if !ok {
+			break
+		}
+		names = append([]string{parent.Name}, names...)
+		pool = parent
+	}
+	return strings.Join(names, "/")
+}
+
+func resourcePoolType(moType string) string {
+	if moType == "VirtualApp" {
+		return "vapp"
+	}
+	return "resource_pool"
+}
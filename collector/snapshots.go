@@ -0,0 +1,160 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// SnapshotDetails enables the per-snapshot series, which can get large on
+// environments with many snapshots
+var SnapshotDetails = false
+
+var (
+	vmSnapshotLabels              []string = []string{"machine_name", "datacenter", "cluster_name", "snapshot_name", "snapshot_id"}
+	vmSnapshotConsolidationNeeded          = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "consolidation_needed",
+			Help:      "VM disks need consolidation (1 = true, 0 = false)",
+		},
+		vmLabels,
+	)
+	vmSnapshotCount = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "snapshots_total",
+			Help:      "VM number of snapshots",
+		},
+		vmLabels,
+	)
+	vmSnapshotDepth = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "snapshot_tree_depth",
+			Help:      "VM snapshot tree depth",
+		},
+		vmLabels,
+	)
+	vmSnapshotOldest = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "snapshot_oldest_timestamp_seconds",
+			Help:      "VM oldest snapshot creation time in seconds since epoch",
+		},
+		vmLabels,
+	)
+	vmSnapshotSize = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "snapshot_size_bytes",
+			Help:      "VM total snapshot disk size in bytes",
+		},
+		vmLabels,
+	)
+	vmSnapshotTimestamp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "snapshot_timestamp_seconds",
+			Help:      "VM snapshot creation time in seconds since epoch",
+		},
+		vmSnapshotLabels,
+	)
+)
+
+func exportVirtualMachineSnapshotMetrics(vm mo.VirtualMachine, labels prometheus.Labels) {
+	var snapshots []types.VirtualMachineSnapshotTree
+	depth := 0
+	if vm.Snapshot != nil {
+		snapshots, depth = flattenSnapshotTree(vm.Snapshot.RootSnapshotList, 1)
+	}
+
+	var oldest float64
+	for _, snapshot := range snapshots {
+		created := float64(snapshot.CreateTime.Unix())
+		if oldest == 0 || created < oldest {
+			oldest = created
+		}
+		if SnapshotDetails {
+			vmSnapshotTimestamp.With(prometheus.Labels{
+				"machine_name":  labels["machine_name"],
+				"datacenter":    labels["datacenter"],
+				"cluster_name":  labels["cluster_name"],
+				"snapshot_name": snapshot.Name,
+				"snapshot_id":   snapshot.Snapshot.Value,
+			}).Set(created)
+		}
+	}
+
+	consolidationNeeded := 0.0
+	if vm.Runtime.ConsolidationNeeded != nil && *vm.Runtime.ConsolidationNeeded {
+		consolidationNeeded = 1
+	}
+
+	vmSnapshotConsolidationNeeded.With(labels).Set(consolidationNeeded)
+	vmSnapshotCount.With(labels).Set(float64(len(snapshots)))
+	vmSnapshotDepth.With(labels).Set(float64(depth))
+	// VMs without snapshots have no oldest one, 0 would read as 1970
+	if len(snapshots) == 0 {
+		vmSnapshotOldest.Delete(labels)
+	} else {
+		vmSnapshotOldest.With(labels).Set(oldest)
+	}
+	vmSnapshotSize.With(labels).Set(float64(snapshotSize(vm.LayoutEx)))
+}
+
+// flattenSnapshotTree returns all snapshots of the tree and its depth
+func flattenSnapshotTree(tree []types.VirtualMachineSnapshotTree, level int) ([]types.VirtualMachineSnapshotTree, int) {
+	var snapshots []types.VirtualMachineSnapshotTree
+	depth := 0
+	for _, snapshot := range tree {
+		snapshots = append(snapshots, snapshot)
+		children, childDepth := flattenSnapshotTree(snapshot.ChildSnapshotList, level+1)
+		snapshots = append(snapshots, children...)
+		if level > depth {
+			depth = level
+		}
+		if childDepth > depth {
+			depth = childDepth
+		}
+	}
+	return snapshots, depth
+}
+
+// snapshotSize sums the snapshot data and memory files plus every delta disk,
+// i.e. all disk chain links after the base disk
+func snapshotSize(layout *types.VirtualMachineFileLayoutEx) int64 {
+	if layout == nil {
+		return 0
+	}
+
+	keys := make(map[int32]bool)
+	for _, disk := range layout.Disk {
+		for i, unit := range disk.Chain {
+			if i == 0 {
+				continue
+			}
+			for _, key := range unit.FileKey {
+				keys[key] = true
+			}
+		}
+	}
+
+	var size int64
+	for _, file := range layout.File {
+		switch {
+		case file.Type == string(types.VirtualMachineFileLayoutExFileTypeSnapshotData),
+			file.Type == string(types.VirtualMachineFileLayoutExFileTypeSnapshotMemory),
+			keys[file.Key]:
+			size += file.Size
+		}
+	}
+	return size
+}
@@ -0,0 +1,197 @@
+package collector
+
+import (
+	"context"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	storagePodLabels   []string = []string{"datastore_cluster"}
+	storagePodCapacity          = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "datastore_cluster",
+			Name:      "capacity_bytes",
+			Help:      "Datastore cluster capacity in bytes",
+		},
+		storagePodLabels,
+	)
+	storagePodFreeSpace = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "datastore_cluster",
+			Name:      "free_bytes",
+			Help:      "Datastore cluster free space in bytes",
+		},
+		storagePodLabels,
+	)
+	storagePodDatastores = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "datastore_cluster",
+			Name:      "datastores_total",
+			Help:      "Datastore cluster member datastores",
+		},
+		storagePodLabels,
+	)
+	storagePodSdrsEnabled = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "datastore_cluster",
+			Name:      "sdrs_enabled",
+			Help:      "Datastore cluster Storage DRS enabled (1 = enabled, 0 = disabled)",
+		},
+		storagePodLabels,
+	)
+	storagePodSdrsAutomationLevel = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "datastore_cluster",
+			Name:      "sdrs_automation_level",
+			Help:      "Datastore cluster Storage DRS automation level (0 = manual, 1 = automated)",
+		},
+		storagePodLabels,
+	)
+	storagePodSdrsIoLoadBalanceEnabled = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "datastore_cluster",
+			Name:      "sdrs_io_load_balance_enabled",
+			Help:      "Datastore cluster Storage DRS I/O load balancing enabled (1 = enabled, 0 = disabled)",
+		},
+		storagePodLabels,
+	)
+	storagePodSdrsIoLatencyThreshold = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "datastore_cluster",
+			Name:      "sdrs_io_latency_threshold_milliseconds",
+			Help:      "Datastore cluster Storage DRS I/O latency threshold in milliseconds",
+		},
+		storagePodLabels,
+	)
+	storagePodSdrsIoImbalanceThreshold = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "datastore_cluster",
+			Name:      "sdrs_io_load_imbalance_threshold",
+			Help:      "Datastore cluster Storage DRS I/O load imbalance threshold",
+		},
+		storagePodLabels,
+	)
+	storagePodSdrsSpaceThreshold = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "datastore_cluster",
+			Name:      "sdrs_space_utilization_threshold_percent",
+			Help:      "Datastore cluster Storage DRS space utilization threshold in percent",
+		},
+		storagePodLabels,
+	)
+	storagePodSdrsInterval = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "datastore_cluster",
+			Name:      "sdrs_load_balance_interval_minutes",
+			Help:      "Datastore cluster Storage DRS load balancing interval in minutes",
+		},
+		storagePodLabels,
+	)
+
+	storagePodMetrics = []*prometheus.GaugeVec{
+		storagePodCapacity, storagePodFreeSpace, storagePodDatastores, storagePodSdrsEnabled,
+		storagePodSdrsAutomationLevel, storagePodSdrsIoLoadBalanceEnabled, storagePodSdrsIoLatencyThreshold,
+		storagePodSdrsIoImbalanceThreshold, storagePodSdrsSpaceThreshold, storagePodSdrsInterval,
+	}
+)
+
+// exportStoragePodMetrics exports the datastore clusters and returns their
+// names by reference
+func exportStoragePodMetrics(ctx context.Context, c *vim25.Client) (map[types.ManagedObjectReference]string, error) {
+	m := view.NewManager(c)
+
+	v, err := m.CreateContainerView(ctx, c.ServiceContent.RootFolder, []string{"StoragePod"}, true)
+	if err != nil {
+		log.Printf("Error creating container view: %v", err)
+		return nil, err
+	}
+	defer v.Destroy(ctx)
+
+	var pods []mo.StoragePod
+	err = v.Retrieve(ctx, []string{"StoragePod"}, []string{"name", "childEntity", "summary", "podStorageDrsEntry"}, &pods)
+	if err != nil {
+		log.Printf("Error retrieving datastore clusters: %v", err)
+		return nil, err
+	}
+
+	// Removed datastore clusters must disappear
+	for _, gauge := range storagePodMetrics {
+		gauge.Reset()
+	}
+
+	names := make(map[types.ManagedObjectReference]string)
+	for _, pod := range pods {
+		names[pod.Self] = pod.Name
+
+		labels := prometheus.Labels{
+			"datastore_cluster": pod.Name,
+		}
+
+		if pod.Summary != nil {
+			storagePodCapacity.With(labels).Set(
+				float64(pod.Summary.Capacity),
+			)
+			storagePodFreeSpace.With(labels).Set(
+				float64(pod.Summary.FreeSpace),
+			)
+		}
+		storagePodDatastores.With(labels).Set(
+			float64(len(pod.ChildEntity)),
+		)
+
+		if pod.PodStorageDrsEntry == nil {
+			continue
+		}
+		config := pod.PodStorageDrsEntry.StorageDrsConfig.PodConfig
+
+		storagePodSdrsEnabled.With(labels).Set(
+			boolValue(config.Enabled),
+		)
+		storagePodSdrsAutomationLevel.With(labels).Set(
+			boolValue(config.DefaultVmBehavior == string(types.StorageDrsPodConfigInfoBehaviorAutomated)),
+		)
+		storagePodSdrsIoLoadBalanceEnabled.With(labels).Set(
+			boolValue(config.IoLoadBalanceEnabled),
+		)
+		if config.LoadBalanceInterval > 0 {
+			storagePodSdrsInterval.With(labels).Set(
+				float64(config.LoadBalanceInterval),
+			)
+		}
+		if io := config.IoLoadBalanceConfig; io != nil {
+			if io.IoLatencyThreshold > 0 {
+				storagePodSdrsIoLatencyThreshold.With(labels).Set(
+					float64(io.IoLatencyThreshold),
+				)
+			}
+			if io.IoLoadImbalanceThreshold > 0 {
+				storagePodSdrsIoImbalanceThreshold.With(labels).Set(
+					float64(io.IoLoadImbalanceThreshold),
+				)
+			}
+		}
+		if space := config.SpaceLoadBalanceConfig; space != nil && space.SpaceUtilizationThreshold > 0 {
+			storagePodSdrsSpaceThreshold.With(labels).Set(
+				float64(space.SpaceUtilizationThreshold),
+			)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,46 @@
+package collector
+
+import (
+	"context"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/methods"
+)
+
+var (
+	vcenterInfoLabels []string = []string{"name", "version", "build", "api_version", "instance_uuid"}
+	vcenterInfo                = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vcenter",
+			Name:      "info",
+			Help:      "vCenter product version and build, value is always 1",
+		},
+		vcenterInfoLabels,
+	)
+)
+
+func ExportVCenterMetrics(ctx context.Context, client *govmomi.Client) error {
+	// The service content of the client is the one from login, retrieve it
+	// again to see upgrades
+	content, err := methods.GetServiceContent(ctx, client.Client)
+	if err != nil {
+		log.Printf("Error retrieving service content: %v", err)
+		return err
+	}
+	about := content.About
+
+	// An upgraded vCenter must not keep its old version
+	vcenterInfo.Reset()
+	vcenterInfo.With(prometheus.Labels{
+		"name":          about.FullName,
+		"version":       about.Version,
+		"build":         about.Build,
+		"api_version":   about.ApiVersion,
+		"instance_uuid": about.InstanceUuid,
+	}).Set(1)
+	return nil
+}
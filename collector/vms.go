@@ -3,18 +3,21 @@ package collector
 import (
 	"context"
 	"fmt"
+	"log"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/view"
 	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 var (
 	vmLabels          []string = []string{"machine_name", "datacenter", "cluster_name"}
 	vmDatastoreLabels []string = []string{"machine_name", "host_name", "datacenter", "cluster_name", "datastore_id", "datastore_name"}
 	vmDiskLabels      []string = []string{"machine_name", "host_name", "datacenter", "cluster_name", "disk_path"}
+	vmInfoLabels      []string = []string{"machine_name", "datacenter", "cluster_name", "host_name", "guest_os", "guest_id", "hardware_version", "tools_version", "tools_running_status", "power_state", "ip_address", "template"}
 	vmCpuAllocLim              = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "vmware",
@@ -151,6 +154,15 @@ var (
 		},
 		vmDiskLabels,
 	)
+	vmInfo = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "info",
+			Help:      "VM information, value is always 1",
+		},
+		vmInfoLabels,
+	)
 	vmMemoryActive = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "vmware",
@@ -223,6 +235,15 @@ var (
 		},
 		vmLabels,
 	)
+	vmPowerState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "power_state",
+			Help:      "VM power state (0 = poweredOff, 1 = poweredOn, 2 = suspended)",
+		},
+		vmLabels,
+	)
 	vmStorageCommited = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "vmware",
@@ -232,6 +253,15 @@ var (
 		},
 		vmLabels,
 	)
+	vmToolsStatus = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "vmware",
+			Subsystem: "vm",
+			Name:      "tools_status",
+			Help:      "VM VMware Tools status (0 = notInstalled, 1 = notRunning, 2 = old, 3 = ok)",
+		},
+		vmLabels,
+	)
 	vmUptime = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "vmware",
@@ -253,7 +283,7 @@ func ExportVirtualMachineMetrics(ctx context.Context, client *govmomi.Client) er
 	// Create a container view for the datacenters
 	containerView, err := m.CreateContainerView(ctx, c.ServiceContent.RootFolder, []string{"Datacenter"}, true)
 	if err != nil {
-		fmt.Printf("Error creating container view: %v\n", err)
+		log.Printf("Error creating container view: %v", err)
 		return err
 	}
 	defer containerView.Destroy(ctx)
@@ -262,30 +292,58 @@ func ExportVirtualMachineMetrics(ctx context.Context, client *govmomi.Client) er
 	var datacenters []mo.Datacenter
 	err = containerView.Retrieve(ctx, []string{"Datacenter"}, nil, &datacenters)
 	if err != nil {
-		fmt.Printf("Error retrieving datacenters: %v\n", err)
+		log.Printf("Error retrieving datacenters: %v", err)
 		return err
 	}
 
-	// Iterate through the datacenters and list vms
-	for _, dc := range datacenters {
+	// Distributed portgroups the networks cannot be resolved for are labelled
+	// with their portgroup key, which is better than no VM series at all
+	if err = populateNetworkMappings(ctx, c); err != nil {
+		log.Printf("Error retrieving networks: %v", err)
+	}
+
+	// Retrieve the vms of every datacenter before touching any series, so a
+	// failed or slow retrieval does not leave the info series empty
+	vmsByDatacenter := make([][]mo.VirtualMachine, len(datacenters))
+	for i, dc := range datacenters {
 
 		// Create a container view for the vms in the datacenter
 		containerView, err := m.CreateContainerView(ctx, dc.Reference(), []string{"VirtualMachine"}, true)
 		if err != nil {
-			fmt.Printf("Error creating container view for vms: %v\n", err)
+			log.Printf("Error creating container view for vms: %v", err)
 			return err
 		}
 		defer containerView.Destroy(ctx)
 
 		// Retrieve a list of vms in the datacenter
-		var vms []mo.VirtualMachine
-		err = containerView.Retrieve(ctx, []string{"VirtualMachine"}, nil, &vms)
+		err = containerView.Retrieve(ctx, []string{"VirtualMachine"}, nil, &vmsByDatacenter[i])
 		if err != nil {
-			fmt.Printf("Error retrieving vms: %v\n", err)
+			log.Printf("Error retrieving vms: %v", err)
 			return err
 		}
+	}
+
+	// Info series carry the power and tools state as labels, drop the ones
+	// left over from the previous run. Disks are labelled with their
+	// datastore and NICs with their network, which both change with a
+	// migration
+	vmInfo.Reset()
+	vmSnapshotTimestamp.Reset()
+	vmGuestDiskMapping.Reset()
+	vmVirtualDiskInfo.Reset()
+	vmVirtualDiskCapacity.Reset()
+	vmVirtualDiskIopsLimit.Reset()
+	vmVirtualDiskIopsReservation.Reset()
+	vmVirtualDiskShares.Reset()
+	vmNicInfo.Reset()
+	vmNicGuestIp.Reset()
+	vmNicConnected.Reset()
+	vmNicStartConnected.Reset()
+	vmNicGuestConnected.Reset()
 
-		for _, vm := range vms {
+	// Iterate through the datacenters and their vms
+	for i, dc := range datacenters {
+		for _, vm := range vmsByDatacenter[i] {
 			hostID := vm.Summary.Runtime.Host.Value
 
 			clusterName := HostMapping[hostID]
@@ -306,6 +364,24 @@ func ExportVirtualMachineMetrics(ctx context.Context, client *govmomi.Client) er
 				"cluster_name": clusterName,
 			}
 
+			vmInfo.With(vmInfoLabelValues(vm, dc.Name, clusterName, hostName)).Set(1)
+			vmPowerState.With(labels).Set(
+				powerStateValue(vm.Summary.Runtime.PowerState),
+			)
+			vmToolsStatus.With(labels).Set(
+				toolsStatusValue(vm.Guest),
+			)
+
+			exportVirtualMachineSnapshotMetrics(vm, labels)
+			vmHostLabels := prometheus.Labels{
+				"machine_name": vm.Name,
+				"host_name":    hostName,
+				"datacenter":   dc.Name,
+				"cluster_name": clusterName,
+			}
+			exportVirtualMachineDiskMetrics(vm, vmHostLabels)
+			exportVirtualMachineNetworkMetrics(vm, vmHostLabels)
+
 			// collect datastore metrics
 			for _, storage := range vm.Storage.PerDatastoreUsage {
 				datastoreId := storage.Datastore.Value
@@ -418,3 +494,58 @@ func ExportVirtualMachineMetrics(ctx context.Context, client *govmomi.Client) er
 	}
 	return nil
 }
+
+func vmInfoLabelValues(vm mo.VirtualMachine, datacenter, clusterName, hostName string) prometheus.Labels {
+	labels := prometheus.Labels{
+		"machine_name":         vm.Name,
+		"datacenter":           datacenter,
+		"cluster_name":         clusterName,
+		"host_name":            hostName,
+		"guest_os":             vm.Summary.Config.GuestFullName,
+		"guest_id":             vm.Summary.Config.GuestId,
+		"hardware_version":     "",
+		"tools_version":        "",
+		"tools_running_status": "",
+		"power_state":          string(vm.Summary.Runtime.PowerState),
+		"ip_address":           "",
+		"template":             fmt.Sprintf("%t", vm.Summary.Config.Template),
+	}
+	if vm.Config != nil {
+		labels["hardware_version"] = vm.Config.Version
+		labels["guest_id"] = vm.Config.GuestId
+	}
+	if vm.Guest != nil {
+		labels["tools_version"] = vm.Guest.ToolsVersion
+		labels["tools_running_status"] = vm.Guest.ToolsRunningStatus
+		labels["ip_address"] = vm.Guest.IpAddress
+		if vm.Guest.GuestFullName != "" {
+			labels["guest_os"] = vm.Guest.GuestFullName
+		}
+	}
+	return labels
+}
+
+func powerStateValue(state types.VirtualMachinePowerState) float64 {
+	switch state {
+	case types.VirtualMachinePowerStatePoweredOn:
+		return 1
+	case types.VirtualMachinePowerStateSuspended:
+		return 2
+	}
+	return 0
+}
+
+func toolsStatusValue(guest *types.GuestInfo) float64 {
+	if guest == nil {
+		return 0
+	}
+	switch guest.ToolsStatus {
+	case types.VirtualMachineToolsStatusToolsNotRunning:
+		return 1
+	case types.VirtualMachineToolsStatusToolsOld:
+		return 2
+	case types.VirtualMachineToolsStatusToolsOk:
+		return 3
+	}
+	return 0
+}
@@ -0,0 +1,260 @@
+package collector
+
+import (
+	"context"
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	vmPerfLabels  []string = []string{"machine_name", "host_name", "datacenter", "cluster_name", "counter_instance"}
+	vmPerfMetrics          = []perfMetric{
+		{
+			counter: "cpu.ready.summation",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "vm",
+					Name:      "cpu_ready_milliseconds",
+					Help:      "VM CPU ready time in milliseconds over the 20s sample",
+				},
+				vmPerfLabels,
+			),
+		},
+		{
+			counter: "cpu.costop.summation",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "vm",
+					Name:      "cpu_costop_milliseconds",
+					Help:      "VM CPU co-stop time in milliseconds over the 20s sample",
+				},
+				vmPerfLabels,
+			),
+		},
+		{
+			counter: "cpu.swapwait.summation",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "vm",
+					Name:      "cpu_swapwait_milliseconds",
+					Help:      "VM CPU swap wait time in milliseconds over the 20s sample",
+				},
+				vmPerfLabels,
+			),
+		},
+		{
+			counter: "virtualDisk.totalReadLatency.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "vm",
+					Name:      "disk_read_latency_milliseconds",
+					Help:      "VM virtual disk read latency in milliseconds",
+				},
+				vmPerfLabels,
+			),
+		},
+		{
+			counter: "virtualDisk.totalWriteLatency.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "vm",
+					Name:      "disk_write_latency_milliseconds",
+					Help:      "VM virtual disk write latency in milliseconds",
+				},
+				vmPerfLabels,
+			),
+		},
+		{
+			counter: "virtualDisk.numberReadAveraged.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "vm",
+					Name:      "disk_read_iops",
+					Help:      "VM virtual disk read operations per second",
+				},
+				vmPerfLabels,
+			),
+		},
+		{
+			counter: "virtualDisk.numberWriteAveraged.average",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "vm",
+					Name:      "disk_write_iops",
+					Help:      "VM virtual disk write operations per second",
+				},
+				vmPerfLabels,
+			),
+		},
+		{
+			counter: "virtualDisk.read.average",
+			scale:   1024,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "vm",
+					Name:      "disk_read_bytes_per_second",
+					Help:      "VM virtual disk read throughput in bytes per second",
+				},
+				vmPerfLabels,
+			),
+		},
+		{
+			counter: "virtualDisk.write.average",
+			scale:   1024,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "vm",
+					Name:      "disk_write_bytes_per_second",
+					Help:      "VM virtual disk write throughput in bytes per second",
+				},
+				vmPerfLabels,
+			),
+		},
+		{
+			counter: "net.received.average",
+			scale:   1024,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "vm",
+					Name:      "net_received_bytes_per_second",
+					Help:      "VM network receive throughput in bytes per second",
+				},
+				vmPerfLabels,
+			),
+		},
+		{
+			counter: "net.transmitted.average",
+			scale:   1024,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "vm",
+					Name:      "net_transmitted_bytes_per_second",
+					Help:      "VM network transmit throughput in bytes per second",
+				},
+				vmPerfLabels,
+			),
+		},
+		{
+			counter: "net.droppedRx.summation",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "vm",
+					Name:      "net_dropped_rx_packets",
+					Help:      "VM received packets dropped over the 20s sample",
+				},
+				vmPerfLabels,
+			),
+		},
+		{
+			counter: "net.droppedTx.summation",
+			scale:   1,
+			gauge: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: "vmware",
+					Subsystem: "vm",
+					Name:      "net_dropped_tx_packets",
+					Help:      "VM transmitted packets dropped over the 20s sample",
+				},
+				vmPerfLabels,
+			),
+		},
+	}
+)
+
+func ExportVirtualMachinePerfMetrics(ctx context.Context, client *govmomi.Client) error {
+
+	c := client.Client
+
+	// Create a view manager
+	m := view.NewManager(c)
+
+	// Create a container view for the datacenters
+	containerView, err := m.CreateContainerView(ctx, c.ServiceContent.RootFolder, []string{"Datacenter"}, true)
+	if err != nil {
+		log.Printf("Error creating container view: %v", err)
+		return err
+	}
+	defer containerView.Destroy(ctx)
+
+	// Retrieve a list of datacenters
+	var datacenters []mo.Datacenter
+	err = containerView.Retrieve(ctx, []string{"Datacenter"}, []string{"name"}, &datacenters)
+	if err != nil {
+		log.Printf("Error retrieving datacenters: %v", err)
+		return err
+	}
+
+	// Realtime stats only exist for powered on vms, collect the labels of
+	// every one of them so they can be queried in batches
+	entities := make(map[types.ManagedObjectReference]prometheus.Labels)
+	for _, dc := range datacenters {
+
+		// Create a container view for the vms in the datacenter
+		containerView, err := m.CreateContainerView(ctx, dc.Reference(), []string{"VirtualMachine"}, true)
+		if err != nil {
+			log.Printf("Error creating container view for vms: %v", err)
+			return err
+		}
+		defer containerView.Destroy(ctx)
+
+		var vms []mo.VirtualMachine
+		err = containerView.Retrieve(ctx, []string{"VirtualMachine"}, []string{"name", "runtime"}, &vms)
+		if err != nil {
+			log.Printf("Error retrieving vms: %v", err)
+			return err
+		}
+
+		for _, vm := range vms {
+			if vm.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn || vm.Runtime.Host == nil {
+				continue
+			}
+			hostID := vm.Runtime.Host.Value
+
+			clusterName := HostMapping[hostID]
+			if clusterName == "" {
+				clusterName = "none"
+			}
+
+			hostName := VirtualMachineMapping[hostID]
+			if hostName == "" {
+				hostName = "unknown"
+			}
+
+			entities[vm.Self] = prometheus.Labels{
+				"machine_name": vm.Name,
+				"host_name":    hostName,
+				"datacenter":   dc.Name,
+				"cluster_name": clusterName,
+			}
+		}
+	}
+
+	metrics := selectedPerfMetrics("vm")
+	return exportPerfMetrics(ctx, c, entities, metrics, perfInstanceLabels)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/performance"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// counter is a PerformanceManager counter as printed by list-counters
+type counter struct {
+	Name        string   `json:"name"`
+	Group       string   `json:"group"`
+	Counter     string   `json:"counter"`
+	Rollup      string   `json:"rollup"`
+	Unit        string   `json:"unit"`
+	Level       int32    `json:"level"`
+	Description string   `json:"description"`
+	Instances   []string `json:"instances,omitempty"`
+}
+
+// listCounters implements the list-counters subcommand, printing every
+// counter the vCenter supports or, with -entity, the counters available for
+// that entity
+func listCounters(args []string) error {
+	flags := flag.NewFlagSet("list-counters", flag.ExitOnError)
+	entity := flags.String("entity", "", "only list the counters available for this entity, by name or managed object reference (e.g. HostSystem:host-42)")
+	interval := flags.Int("interval", 20, "sampling interval in seconds to list the available counters for, 20 is realtime")
+	format := flags.String("format", "table", "output format, table or json")
+	flags.Parse(args)
+
+	if *format != "table" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	ctx := context.Background()
+	client, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Logout(ctx)
+
+	pm := performance.NewManager(client.Client)
+	info, err := pm.CounterInfoByKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	var counters []counter
+	if *entity == "" {
+		for _, c := range info {
+			counters = append(counters, newCounter(c))
+		}
+	} else {
+		ref, err := findEntity(ctx, client, *entity)
+		if err != nil {
+			return err
+		}
+
+		available, err := pm.AvailableMetric(ctx, ref, int32(*interval))
+		if err != nil {
+			return err
+		}
+
+		for key, ids := range available.ByKey() {
+			c, ok := info[key]
+			if !ok {
+				continue
+			}
+			entry := newCounter(c)
+			for _, id := range ids {
+				if id.Instance != "" {
+					entry.Instances = append(entry.Instances, id.Instance)
+				}
+			}
+			sort.Strings(entry.Instances)
+			counters = append(counters, entry)
+		}
+	}
+
+	sort.Slice(counters, func(i, j int) bool {
+		return counters[i].Name < counters[j].Name
+	})
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(counters)
+	}
+	return printCounters(os.Stdout, counters, *entity != "")
+}
+
+func newCounter(c *types.PerfCounterInfo) counter {
+	return counter{
+		Name:        c.Name(),
+		Group:       c.GroupInfo.GetElementDescription().Key,
+		Counter:     c.NameInfo.GetElementDescription().Key,
+		Rollup:      string(c.RollupType),
+		Unit:        c.UnitInfo.GetElementDescription().Key,
+		Level:       c.Level,
+		Description: c.NameInfo.GetElementDescription().Summary,
+	}
+}
+
+func printCounters(w io.Writer, counters []counter, instances bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := "NAME\tGROUP\tCOUNTER\tROLLUP\tUNIT\tLEVEL\tDESCRIPTION"
+	if instances {
+		header += "\tINSTANCES"
+	}
+	fmt.Fprintln(tw, header)
+
+	for _, c := range counters {
+		row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%d\t%s", c.Name, c.Group, c.Counter, c.Rollup, c.Unit, c.Level, c.Description)
+		if instances {
+			row += "\t" + strings.Join(c.Instances, ",")
+		}
+		fmt.Fprintln(tw, row)
+	}
+	return tw.Flush()
+}
+
+// findEntity resolves the name of a VM, host, cluster or datastore, or a
+// managed object reference (Type:value)
+func findEntity(ctx context.Context, client *govmomi.Client, entity string) (types.ManagedObjectReference, error) {
+	var ref types.ManagedObjectReference
+
+	m := view.NewManager(client.Client)
+	kinds := []string{"VirtualMachine", "HostSystem", "ClusterComputeResource", "Datastore"}
+	v, err := m.CreateContainerView(ctx, client.ServiceContent.RootFolder, kinds, true)
+	if err != nil {
+		return ref, err
+	}
+	defer v.Destroy(ctx)
+
+	var objects []mo.ManagedEntity
+	if err = v.Retrieve(ctx, kinds, []string{"name"}, &objects); err != nil {
+		return ref, err
+	}
+
+	var matches []types.ManagedObjectReference
+	for _, object := range objects {
+		if object.Name == entity {
+			matches = append(matches, object.Self)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if ref.FromString(entity) {
+			return ref, nil
+		}
+		return ref, fmt.Errorf("entity %q not found", entity)
+	case 1:
+		return matches[0], nil
+	}
+	return ref, fmt.Errorf("entity %q is ambiguous, use one of %v", entity, matches)
+}
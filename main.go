@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -22,10 +23,36 @@ var (
 	insecure        = os.Getenv("VSPHERE_INSECURE") == "true"
 	password        = os.Getenv("VSPHERE_PASSWORD")
 	username        = os.Getenv("VSPHERE_USERNAME")
+	snapshotDetails = os.Getenv("VM_SNAPSHOT_DETAILS") == "true"
+	eventTypes      = os.Getenv("EVENT_TYPES")
+	checkpointFile  = os.Getenv("EVENTS_CHECKPOINT_FILE")
+	eventsLog       = os.Getenv("EVENTS_LOG")
+	forecastWindow  = os.Getenv("DS_FORECAST_WINDOW")
+	orphanedFiles   = os.Getenv("ORPHANED_FILES_INTERVAL")
 	metricsPort     = 8080
 	pollingInterval = 5 * time.Minute // in minutes
 )
 
+// perfCounters reads the PERF_COUNTERS_<ENTITY> comma separated counter lists,
+// "none" disables the performance metrics of that entity type
+func perfCounters() map[string][]string {
+	counters := make(map[string][]string)
+	for _, entityType := range []string{"vm", "host", "datastore"} {
+		value, ok := os.LookupEnv("PERF_COUNTERS_" + strings.ToUpper(entityType))
+		if !ok {
+			continue
+		}
+		counters[entityType] = []string{}
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" && name != "none" {
+				counters[entityType] = append(counters[entityType], name)
+			}
+		}
+	}
+	return counters
+}
+
 func collectMetrics(ctx context.Context, client *govmomi.Client) {
 	for {
 		select {
@@ -39,6 +66,14 @@ func collectMetrics(ctx context.Context, client *govmomi.Client) {
 			}
 			elapsed := time.Since(start)
 			log.Printf("Cluster metrics retrieval took %s", elapsed)
+			start = time.Now()
+			err = collector.ExportResourcePoolMetrics(ctx, client)
+			if err != nil {
+				log.Printf("Error exporting metrics: %v", err)
+			}
+			elapsed = time.Since(start)
+			log.Printf("Resource pool metrics retrieval took %s", elapsed)
+			start = time.Now()
 			err = collector.ExportDatastoresMetrics(ctx, client)
 			if err != nil {
 				log.Printf("Error exporting metrics: %v", err)
@@ -53,40 +88,186 @@ func collectMetrics(ctx context.Context, client *govmomi.Client) {
 			elapsed = time.Since(start)
 			log.Printf("Host metrics retrieval took %s", elapsed)
 			start = time.Now()
+			err = collector.ExportHostPerfMetrics(ctx, client)
+			if err != nil {
+				log.Printf("Error exporting metrics: %v", err)
+			}
+			elapsed = time.Since(start)
+			log.Printf("Host performance metrics retrieval took %s", elapsed)
+			start = time.Now()
+			err = collector.ExportDatastorePerfMetrics(ctx, client)
+			if err != nil {
+				log.Printf("Error exporting metrics: %v", err)
+			}
+			elapsed = time.Since(start)
+			log.Printf("Datastore performance metrics retrieval took %s", elapsed)
+			start = time.Now()
 			err = collector.ExportVirtualMachineMetrics(ctx, client)
 			if err != nil {
 				log.Printf("Error exporting metrics: %v", err)
 			}
 			elapsed = time.Since(start)
 			log.Printf("VM metrics retrieval took %s", elapsed)
+			start = time.Now()
+			err = collector.ExportVirtualMachinePerfMetrics(ctx, client)
+			if err != nil {
+				log.Printf("Error exporting metrics: %v", err)
+			}
+			elapsed = time.Since(start)
+			log.Printf("VM performance metrics retrieval took %s", elapsed)
+			start = time.Now()
+			err = collector.ExportAlarmMetrics(ctx, client)
+			if err != nil {
+				log.Printf("Error exporting metrics: %v", err)
+			}
+			elapsed = time.Since(start)
+			log.Printf("Alarm metrics retrieval took %s", elapsed)
+			start = time.Now()
+			err = collector.ExportNetworkMetrics(ctx, client)
+			if err != nil {
+				log.Printf("Error exporting metrics: %v", err)
+			}
+			elapsed = time.Since(start)
+			log.Printf("Network metrics retrieval took %s", elapsed)
+			start = time.Now()
+			err = collector.ExportEventMetrics(ctx, client)
+			if err != nil {
+				log.Printf("Error exporting metrics: %v", err)
+			}
+			elapsed = time.Since(start)
+			log.Printf("Event metrics retrieval took %s", elapsed)
+			start = time.Now()
+			err = collector.ExportVCenterMetrics(ctx, client)
+			if err != nil {
+				log.Printf("Error exporting metrics: %v", err)
+			}
+			elapsed = time.Since(start)
+			log.Printf("vCenter metrics retrieval took %s", elapsed)
 			log.Printf("collected metrics")
 			time.Sleep(pollingInterval) // Adjust the polling interval as needed
 		}
 	}
 }
 
-func main() {
-
+// newClient logs in to the vSphere server configured by the environment
+func newClient(ctx context.Context) (*govmomi.Client, error) {
 	// Create a URL object
 	u, err := soap.ParseURL(fmt.Sprintf("https://%s/sdk", hostname))
 	if err != nil {
-		fmt.Println("Error parsing vSphere URL:", err)
-		return
+		return nil, fmt.Errorf("parsing vSphere URL: %w", err)
 	}
 	u.User = url.UserPassword(username, password)
 
 	// Create a vSphere client
-	ctx := context.Background()
 	client, err := govmomi.NewClient(ctx, u, insecure)
 	if err != nil {
-		fmt.Println("Error creating vSphere client:", err)
+		return nil, fmt.Errorf("creating vSphere client: %w", err)
+	}
+	return client, nil
+}
+
+// collectOrphanedFiles searches the datastores for orphaned disks every
+// OrphanedFilesInterval, so a long search does not delay the other metrics
+func collectOrphanedFiles(ctx context.Context, client *govmomi.Client) {
+	ticker := time.NewTicker(collector.OrphanedFilesInterval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		err := collector.ExportOrphanedFileMetrics(ctx, client)
+		if err != nil {
+			log.Printf("Error exporting metrics: %v", err)
+		}
+		elapsed := time.Since(start)
+		log.Printf("Orphaned files metrics retrieval took %s", elapsed)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func main() {
+
+	if len(os.Args) > 1 && os.Args[1] == "list-counters" {
+		if err := listCounters(os.Args[2:]); err != nil {
+			fmt.Println("Error listing counters:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	collector.SnapshotDetails = snapshotDetails
+	collector.PerfCounters = perfCounters()
+	collector.EventsCheckpointFile = checkpointFile
+	if eventTypes != "" {
+		var types []string
+		for _, name := range strings.Split(eventTypes, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				types = append(types, name)
+			}
+		}
+		if len(types) > 0 {
+			collector.EventTypes = types
+		}
+	}
+	if forecastWindow != "" {
+		window, err := time.ParseDuration(forecastWindow)
+		if err != nil {
+			fmt.Println("Error parsing DS_FORECAST_WINDOW:", err)
+			return
+		}
+		collector.DatastoreForecastWindow = window
+	}
+	if orphanedFiles != "" {
+		interval, err := time.ParseDuration(orphanedFiles)
+		if err != nil {
+			fmt.Println("Error parsing ORPHANED_FILES_INTERVAL:", err)
+			return
+		}
+		collector.OrphanedFilesInterval = interval
+	}
+	switch eventsLog {
+	case "":
+	case "stdout":
+		collector.EventsLog = os.Stdout
+	default:
+		f, err := os.OpenFile(eventsLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			fmt.Println("Error opening events log:", err)
+			return
+		}
+		defer f.Close()
+		collector.EventsLog = f
+	}
+
+	ctx := context.Background()
+	client, err := newClient(ctx)
+	if err != nil {
+		fmt.Println("Error", err)
 		return
 	}
 	defer client.Logout(ctx)
 
+	// Validate the performance counter selection before serving anything
+	err = collector.ResolvePerfCounters(ctx, client)
+	if err != nil {
+		fmt.Println("Error resolving performance counters:", err)
+		return
+	}
+
 	http.Handle("/metrics", promhttp.Handler())
+	if collector.OrphanedFilesInterval > 0 {
+		http.HandleFunc("/orphaned-files", collector.OrphanedFilesHandler)
+	}
 	go http.ListenAndServe(fmt.Sprintf(":%d", metricsPort), nil)
 
+	if collector.OrphanedFilesInterval > 0 {
+		go collectOrphanedFiles(ctx, client)
+	}
+
 	collectMetrics(ctx, client)
 
 }